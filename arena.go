@@ -0,0 +1,100 @@
+package goson
+
+import "unsafe"
+
+// Arena hands out the nodes and string bytes of parsed Values from large
+// slabs instead of one heap object at a time. Set it as Parser.Arena to
+// parse into it. Reset makes all of its memory available again at once:
+// every Value parsed into the arena, and every string or slice taken from
+// one, must not be used after Reset. Clone copies a Value out of the
+// arena; copies made by the package itself, such as those stored by
+// Map.Set or returned by Interface and Decode, clone arena strings
+// already. Slices handed out by the arena have
+// no spare capacity, so appending to them moves them to the heap rather
+// than overwriting a neighbour. The zero Arena is ready to use. An Arena
+// is not safe for concurrent use.
+type Arena struct {
+	values  slab[Value]
+	kvs     slab[KV]
+	elems   slab[*Value]
+	members slab[*KV]
+	bytes   slab[byte]
+}
+
+func (a *Arena) value() *Value {
+	return &a.values.alloc(1)[0]
+}
+
+func (a *Arena) kv() *KV {
+	return &a.kvs.alloc(1)[0]
+}
+
+func (a *Arena) elemSlice(elems []*Value) []*Value {
+	s := a.elems.alloc(len(elems))
+	copy(s, elems)
+	return s
+}
+
+func (a *Arena) memberSlice(members []*KV) []*KV {
+	s := a.members.alloc(len(members))
+	copy(s, members)
+	return s
+}
+
+func (a *Arena) string(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	s := a.bytes.alloc(len(b))
+	copy(s, b)
+	return unsafe.String(&s[0], len(s))
+}
+
+// Reset releases everything allocated from a for reuse. When the last
+// round needed more than one slab of some kind, the slabs are replaced by
+// a single one large enough for all of it, so that a steady workload
+// settles into one slab per kind.
+func (a *Arena) Reset() {
+	a.values.reset()
+	a.kvs.reset()
+	a.elems.reset()
+	a.members.reset()
+	a.bytes.reset()
+}
+
+const minSlabSize = 64
+
+type slab[T any] struct {
+	buf  []T
+	used int
+}
+
+func (s *slab[T]) alloc(n int) []T {
+	if len(s.buf)+n > cap(s.buf) {
+		size := 2 * cap(s.buf)
+		if size < minSlabSize {
+			size = minSlabSize
+		}
+		if size < n {
+			size = n
+		}
+		s.buf = make([]T, 0, size)
+	}
+	i := len(s.buf)
+	s.buf = s.buf[:i+n]
+	s.used += n
+	return s.buf[i : i+n : i+n]
+}
+
+func (s *slab[T]) reset() {
+	if s.used > cap(s.buf) {
+		s.buf = make([]T, 0, s.used)
+	} else {
+		var zero T
+		for i := range s.buf {
+			s.buf[i] = zero
+		}
+		s.buf = s.buf[:0]
+	}
+	s.used = 0
+}
@@ -0,0 +1,139 @@
+package goson
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+// arenaBytes returns the number of bytes handed out by a since its last
+// Reset, which is the memory a document parsed into it occupies.
+func arenaBytes(a *Arena) int {
+	return a.values.used*int(unsafe.Sizeof(Value{})) +
+		a.kvs.used*int(unsafe.Sizeof(KV{})) +
+		a.elems.used*int(unsafe.Sizeof((*Value)(nil))) +
+		a.members.used*int(unsafe.Sizeof((*KV)(nil))) +
+		a.bytes.used
+}
+
+func benchmarkDocument() string {
+	var b strings.Builder
+	b.WriteString("[")
+	for i := 0; i < 1000; i++ {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("{\"id\":" + strconv.Itoa(i) + ",\"name\":\"user " + strconv.Itoa(i) +
+			"\",\"active\":true,\"tags\":[\"a\",\"b\\n\",\"c\"],\"score\":1500,\"parent\":null}")
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+func TestParseArena(t *testing.T) {
+	s := benchmarkDocument()
+	var a Arena
+	var roots []*Value
+	for i := 0; i < 3; i++ {
+		p := Parser{Arena: &a}
+		v, err := p.Parse(s)
+		assert.Nil(t, err)
+		assert.True(t, isEqual(parseValue(t, s), v))
+		assert.Equal(t, s, stringify(t, v))
+		assert.Equal(t, len(v.arr()), cap(v.arr()))
+		roots = append(roots, v)
+		a.Reset()
+	}
+	// The first Reset merges the slabs, after which they are reused.
+	assert.NotSame(t, roots[0], roots[1])
+	assert.Same(t, roots[1], roots[2])
+
+	p := Parser{Arena: &a}
+	v, err := p.Parse("{\"a\":[1],\"b\":\"x\"}")
+	assert.Nil(t, err)
+	e, _ := v.getObjectValue("a")
+	_ = e.insertArrayElement(NewNumber(2), 1)
+	assert.Equal(t, "{\"a\":[1,2],\"b\":\"x\"}", stringify(t, v))
+	assert.Equal(t, 2, len(v.obj()))
+	assert.Equal(t, 2, cap(v.obj()))
+
+	_, err = p.Parse("[1,{\"a\":[2,}]")
+	assert.Equal(t, ErrParseInvalidValue, err)
+	assert.Equal(t, 0, len(p.elems))
+	assert.Equal(t, 0, len(p.members))
+}
+
+func TestArenaStringsEscape(t *testing.T) {
+	var a Arena
+	p := Parser{Arena: &a}
+	v, err := p.Parse("{\"key\":\"value\",\"a\":[\"elem\"]}")
+	assert.Nil(t, err)
+	clone, cp, x := v.Clone(), v.copy(), v.Interface()
+	var d struct {
+		Key string
+		A   []string
+	}
+	var m map[string]any
+	assert.Nil(t, Decode(v, &d))
+	assert.Nil(t, Decode(v, &m))
+
+	a.Reset()
+	_, err = p.Parse("{\"xxx\":\"xxxxx\",\"x\":[\"xxxx\"]}")
+	assert.Nil(t, err)
+
+	want := "{\"key\":\"value\",\"a\":[\"elem\"]}"
+	assert.Equal(t, want, stringify(t, clone))
+	assert.Equal(t, want, stringify(t, cp))
+	assert.Equal(t, map[string]any{"key": "value", "a": []any{"elem"}}, x)
+	assert.Equal(t, map[string]any{"key": "value", "a": []any{"elem"}}, m)
+	assert.Equal(t, "value", d.Key)
+	assert.Equal(t, []string{"elem"}, d.A)
+}
+
+func TestClone(t *testing.T) {
+	v := parseValue(t, "{\"s\":\"abc\",\"a\":[1,{\"b\":null}]}")
+	c := v.Clone()
+	assert.True(t, isEqual(v, c))
+	s, _ := v.getObjectValue("s")
+	cs, _ := c.getObjectValue("s")
+	assert.NotSame(t, s, cs)
+	assert.True(t, unsafe.StringData(s.str()) != unsafe.StringData(cs.str()))
+	assert.False(t, c.arena)
+}
+
+func TestCloneDuplicateKeys(t *testing.T) {
+	v := parseValue(t, "{\"a\":1,\"a\":2,\"b\":[{\"c\":3,\"c\":4}]}")
+	for _, c := range []*Value{v.Clone(), v.copy()} {
+		assert.True(t, isEqual(v, c))
+		assert.Equal(t, stringify(t, v), stringify(t, c))
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	s := benchmarkDocument()
+	b.SetBytes(int64(len(s)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var p Parser
+		if _, err := p.Parse(s); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseArena(b *testing.B) {
+	s := benchmarkDocument()
+	b.SetBytes(int64(len(s)))
+	b.ReportAllocs()
+	var a Arena
+	for i := 0; i < b.N; i++ {
+		p := Parser{Arena: &a}
+		if _, err := p.Parse(s); err != nil {
+			b.Fatal(err)
+		}
+		b.ReportMetric(float64(arenaBytes(&a)), "doc-B")
+		a.Reset()
+	}
+}
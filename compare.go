@@ -0,0 +1,124 @@
+package goson
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// Compare orders two Values, returning -1, 0 or +1. Values of different
+// types order as null < false < true < number < string < array < object.
+// Numbers compare numerically with NaN equal to itself and below every
+// other number, strings compare byte-wise and arrays element by element.
+// Objects compare as their members sorted by key, so member order does
+// not matter; when a key repeats, the first member counts, as with
+// getObjectValue. Compare returns 0 exactly when Equal with no options
+// reports true.
+func Compare(a, b *Value) int {
+	if a.t != b.t {
+		if a.t < b.t {
+			return -1
+		}
+		return 1
+	}
+	switch a.t {
+	case NUMBER:
+		return compareNumber(a.num(), b.num())
+	case STRING:
+		return strings.Compare(a.str(), b.str())
+	case ARRAY:
+		aa, ba := a.arr(), b.arr()
+		for i := 0; i < len(aa) && i < len(ba); i++ {
+			if c := Compare(aa[i], ba[i]); c != 0 {
+				return c
+			}
+		}
+		return compareInt(len(aa), len(ba))
+	case OBJECT:
+		am, bm := sortedMembers(a), sortedMembers(b)
+		for i := 0; i < len(am) && i < len(bm); i++ {
+			if c := strings.Compare(am[i].k, bm[i].k); c != 0 {
+				return c
+			}
+			if c := Compare(am[i].v, bm[i].v); c != 0 {
+				return c
+			}
+		}
+		return compareInt(len(am), len(bm))
+	default:
+		return 0
+	}
+}
+
+func compareNumber(a, b float64) int {
+	switch {
+	case math.IsNaN(a) && math.IsNaN(b):
+		return 0
+	case math.IsNaN(a) || a < b:
+		return -1
+	case math.IsNaN(b) || a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// sortedMembers returns the distinct members of an object sorted by key,
+// keeping the first member for a repeated key.
+func sortedMembers(v *Value) []*KV {
+	members := make([]*KV, len(v.obj()))
+	copy(members, v.obj())
+	sort.SliceStable(members, func(i, j int) bool { return members[i].k < members[j].k })
+	n := 0
+	for i, kv := range members {
+		if i > 0 && kv.k == members[n-1].k {
+			continue
+		}
+		members[n] = kv
+		n++
+	}
+	return members[:n]
+}
+
+// SortArray sorts the elements of the array v in ascending Compare order.
+// With a path, elements are ordered by the node found at that path inside
+// each of them, and elements where it is missing sort first. The sort is
+// stable.
+func SortArray(v *Value, byPath ...any) error {
+	if v.t != ARRAY {
+		return ErrPathTypeMismatch
+	}
+	if v.frozen {
+		return ErrValueFrozen
+	}
+	a := v.arr()
+	keys := make(map[*Value]*Value, len(a))
+	for _, e := range a {
+		if k, err := e.Lookup(byPath...); err == nil {
+			keys[e] = k
+		}
+	}
+	sort.SliceStable(a, func(i, j int) bool {
+		ki, kj := keys[a[i]], keys[a[j]]
+		switch {
+		case ki == nil:
+			return kj != nil
+		case kj == nil:
+			return false
+		default:
+			return Compare(ki, kj) < 0
+		}
+	})
+	return nil
+}
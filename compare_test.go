@@ -0,0 +1,40 @@
+package goson
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	order := []string{
+		"null", "false", "true", "-1e10", "0", "1.5", "\"\"", "\"a\"", "\"ab\"", "\"b\"",
+		"[]", "[1]", "[1,2]", "[2]", "{}", "{\"a\":1}", "{\"a\":1,\"b\":1}", "{\"a\":2}", "{\"b\":0}",
+	}
+	for i, l := range order {
+		for j, r := range order {
+			e := compareInt(i, j)
+			assert.Equal(t, e, Compare(parseValue(t, l), parseValue(t, r)), l+" vs "+r)
+		}
+	}
+
+	assert.Equal(t, 0, Compare(parseValue(t, "{\"a\":1,\"b\":[2]}"), parseValue(t, "{\"b\":[2],\"a\":1}")))
+	assert.Equal(t, 0, Compare(parseValue(t, "{\"a\":1,\"a\":2}"), parseValue(t, "{\"a\":1}")))
+	assert.Equal(t, 0, Compare(parseValue(t, "0"), parseValue(t, "-0")))
+	assert.Equal(t, -1, Compare(NewNumber(math.NaN()), NewNumber(math.Inf(-1))))
+	assert.Equal(t, 0, Compare(NewNumber(math.NaN()), NewNumber(math.NaN())))
+}
+
+func TestSortArray(t *testing.T) {
+	v := parseValue(t, "[{},\"b\",1,null,[0],true,\"a\",false,{\"x\":1},0.5]")
+	assert.Nil(t, SortArray(v))
+	assert.Equal(t, "[null,false,true,0.5,1,\"a\",\"b\",[0],{},{\"x\":1}]", stringify(t, v))
+
+	v = parseValue(t, "[{\"n\":\"c\",\"a\":{\"g\":3}},{\"n\":\"a\"},{\"n\":\"b\",\"a\":{\"g\":1}},{\"n\":\"d\",\"a\":{\"g\":1}}]")
+	assert.Nil(t, SortArray(v, "a", "g"))
+	assert.Equal(t, "[{\"n\":\"a\"},{\"n\":\"b\",\"a\":{\"g\":1}},{\"n\":\"d\",\"a\":{\"g\":1}},{\"n\":\"c\",\"a\":{\"g\":3}}]",
+		stringify(t, v))
+
+	assert.Equal(t, ErrPathTypeMismatch, SortArray(parseValue(t, "{}")))
+	assert.Equal(t, ErrValueFrozen, SortArray(parseValue(t, "[2,1]").Freeze()))
+}
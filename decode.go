@@ -0,0 +1,356 @@
+package goson
+
+import (
+	"encoding"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Unmarshaler is implemented by types that decode themselves from a Value.
+type Unmarshaler interface {
+	UnmarshalGoson(v *Value) error
+}
+
+type DecodeOptions struct {
+	// DisallowUnknownFields makes an object member that matches no
+	// struct field an error instead of being ignored.
+	DisallowUnknownFields bool
+	// CaseSensitive turns off the case-insensitive fallback used when no
+	// struct field name matches a member exactly.
+	CaseSensitive bool
+}
+
+func Unmarshal(data []byte, x any) error {
+	return DecodeOptions{}.Unmarshal(data, x)
+}
+
+func Decode(v *Value, x any) error {
+	return DecodeOptions{}.Decode(v, x)
+}
+
+// Unmarshal parses data and stores the result in the value pointed to by
+// x, honoring json struct tags.
+func (o DecodeOptions) Unmarshal(data []byte, x any) error {
+	var p Parser
+	v, err := p.Parse(string(data))
+	if err != nil {
+		return err
+	}
+	return o.Decode(v, x)
+}
+
+func (o DecodeOptions) Decode(v *Value, x any) error {
+	rv := reflect.ValueOf(x)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return ErrDecodeInvalidTarget
+	}
+	d := decoder{o}
+	return d.decode(v, rv.Elem(), nil)
+}
+
+// DecodeError reports where in the Value tree a conversion to a Go value
+// failed.
+type DecodeError struct {
+	Path Path
+	Err  error
+}
+
+func (e *DecodeError) Error() string {
+	if len(e.Path) == 0 {
+		return e.Err.Error()
+	}
+	return e.Path.String() + ": " + e.Err.Error()
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// As converts v into a T. Numbers must fit T exactly: 1.5 does not
+// convert to an int and 300 does not convert to an int8.
+func As[T any](v *Value) (T, error) {
+	var t T
+	var d decoder
+	err := d.decode(v, reflect.ValueOf(&t).Elem(), nil)
+	return t, err
+}
+
+// GetAs converts the member key of the object v into a T. When v is not
+// an object or has no such member, the error is the *LookupError of
+// v.Lookup(key).
+func GetAs[T any](v *Value, key string) (T, error) {
+	var t T
+	e, err := v.Lookup(key)
+	if err != nil {
+		return t, err
+	}
+	var d decoder
+	err = d.decode(e, reflect.ValueOf(&t).Elem(), Path{key})
+	return t, err
+}
+
+type decoder struct {
+	opts DecodeOptions
+}
+
+var (
+	unmarshalerType     = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func mismatch(v *Value, rv reflect.Value, path Path) error {
+	return &DecodeError{path, fmt.Errorf("cannot decode %s into %s", v.t, rv.Type())}
+}
+
+func (d *decoder) decode(v *Value, rv reflect.Value, path Path) error {
+	if rv.Kind() != reflect.Pointer && rv.CanAddr() {
+		pv := rv.Addr()
+		if pv.Type().Implements(unmarshalerType) {
+			if err := pv.Interface().(Unmarshaler).UnmarshalGoson(v); err != nil {
+				return &DecodeError{path, err}
+			}
+			return nil
+		}
+		if v.t == STRING && pv.Type().Implements(textUnmarshalerType) {
+			if err := pv.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(v.str())); err != nil {
+				return &DecodeError{path, err}
+			}
+			return nil
+		}
+	}
+
+	switch rv.Type() {
+	case valueType:
+		rv.Set(reflect.ValueOf(*v.copy()))
+		return nil
+	case rawMessageType:
+		s, err := v.stringifyValue()
+		if err != nil {
+			return &DecodeError{path, err}
+		}
+		rv.SetBytes([]byte(s))
+		return nil
+	}
+
+	// As in encoding/json, null sets pointers, interfaces, maps and slices
+	// to nil and leaves anything else as it was.
+	if v.t == NULL {
+		switch rv.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+			rv.SetZero()
+		}
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return d.decode(v, rv.Elem(), path)
+	case reflect.Interface:
+		if rv.NumMethod() != 0 {
+			return &DecodeError{path, fmt.Errorf("cannot decode into non-empty interface %s", rv.Type())}
+		}
+		rv.Set(reflect.ValueOf(v.Interface()))
+	case reflect.Bool:
+		if v.t != TRUE && v.t != FALSE {
+			return mismatch(v, rv, path)
+		}
+		rv.SetBool(v.t == TRUE)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.t != NUMBER {
+			return mismatch(v, rv, path)
+		}
+		n := v.num()
+		if n != math.Trunc(n) {
+			return &DecodeError{path, fmt.Errorf("cannot decode %v into %s: not an integer", n, rv.Type())}
+		}
+		if n < -(1<<63) || n >= 1<<63 || rv.OverflowInt(int64(n)) {
+			return &DecodeError{path, fmt.Errorf("cannot decode %v into %s: out of range", n, rv.Type())}
+		}
+		rv.SetInt(int64(n))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.t != NUMBER {
+			return mismatch(v, rv, path)
+		}
+		n := v.num()
+		if n != math.Trunc(n) {
+			return &DecodeError{path, fmt.Errorf("cannot decode %v into %s: not an integer", n, rv.Type())}
+		}
+		if n < 0 || n >= 1<<64 || rv.OverflowUint(uint64(n)) {
+			return &DecodeError{path, fmt.Errorf("cannot decode %v into %s: out of range", n, rv.Type())}
+		}
+		rv.SetUint(uint64(n))
+	case reflect.Float32, reflect.Float64:
+		if v.t != NUMBER {
+			return mismatch(v, rv, path)
+		}
+		if rv.OverflowFloat(v.num()) {
+			return &DecodeError{path, fmt.Errorf("cannot decode %v into %s: out of range", v.num(), rv.Type())}
+		}
+		rv.SetFloat(v.num())
+	case reflect.String:
+		if v.t != STRING {
+			return mismatch(v, rv, path)
+		}
+		rv.SetString(v.ownString(v.str()))
+	case reflect.Slice:
+		if v.t == STRING && rv.Type().Elem().Kind() == reflect.Uint8 {
+			b, err := base64.StdEncoding.DecodeString(v.str())
+			if err != nil {
+				return &DecodeError{path, err}
+			}
+			rv.SetBytes(b)
+			return nil
+		}
+		if v.t != ARRAY {
+			return mismatch(v, rv, path)
+		}
+		a := v.arr()
+		s := reflect.MakeSlice(rv.Type(), len(a), len(a))
+		for i, e := range a {
+			if err := d.decode(e, s.Index(i), path.append(i)); err != nil {
+				return err
+			}
+		}
+		rv.Set(s)
+	case reflect.Array:
+		if v.t != ARRAY {
+			return mismatch(v, rv, path)
+		}
+		if len(v.arr()) != rv.Len() {
+			return &DecodeError{path, fmt.Errorf("cannot decode array of %d elements into %s", len(v.arr()), rv.Type())}
+		}
+		for i, e := range v.arr() {
+			if err := d.decode(e, rv.Index(i), path.append(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.t != OBJECT {
+			return mismatch(v, rv, path)
+		}
+		t := rv.Type()
+		if rv.IsNil() {
+			rv.Set(reflect.MakeMapWithSize(t, len(v.obj())))
+		}
+		seen := make(map[string]bool, len(v.obj()))
+		for _, kv := range v.obj() {
+			if seen[kv.k] {
+				continue
+			}
+			seen[kv.k] = true
+			k, err := mapKey(v.ownString(kv.k), t.Key())
+			if err != nil {
+				return &DecodeError{path.append(kv.k), err}
+			}
+			e := reflect.New(t.Elem()).Elem()
+			if err := d.decode(kv.v, e, path.append(kv.k)); err != nil {
+				return err
+			}
+			rv.SetMapIndex(k, e)
+		}
+	case reflect.Struct:
+		if v.t != OBJECT {
+			return mismatch(v, rv, path)
+		}
+		fields := cachedFields(rv.Type())
+		seen := make(map[string]bool, len(v.obj()))
+		for _, kv := range v.obj() {
+			if seen[kv.k] {
+				continue
+			}
+			seen[kv.k] = true
+			f := d.lookupField(fields, kv.k)
+			if f == nil {
+				if d.opts.DisallowUnknownFields {
+					return &DecodeError{path.append(kv.k), fmt.Errorf("unknown field %q", kv.k)}
+				}
+				continue
+			}
+			fv, err := fieldByIndex(rv, f.index)
+			if err != nil {
+				return &DecodeError{path.append(kv.k), err}
+			}
+			e := kv.v
+			if f.quoted && e.t == STRING && isQuotable(fv.Kind()) {
+				var p Parser
+				if e, err = p.Parse(e.str()); err != nil {
+					return &DecodeError{path.append(kv.k), fmt.Errorf("invalid quoted value %q", kv.v.str())}
+				}
+			}
+			if err = d.decode(e, fv, path.append(kv.k)); err != nil {
+				return err
+			}
+		}
+	default:
+		return &DecodeError{path, fmt.Errorf("cannot decode into unsupported type %s", rv.Type())}
+	}
+	return nil
+}
+
+func (d *decoder) lookupField(fields []field, name string) *field {
+	for i := range fields {
+		if fields[i].name == name {
+			return &fields[i]
+		}
+	}
+	if d.opts.CaseSensitive {
+		return nil
+	}
+	for i := range fields {
+		if strings.EqualFold(fields[i].name, name) {
+			return &fields[i]
+		}
+	}
+	return nil
+}
+
+func fieldByIndex(rv reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				if !rv.CanSet() {
+					return reflect.Value{}, fmt.Errorf("cannot set embedded pointer to unexported struct %s", rv.Type().Elem())
+				}
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv, nil
+}
+
+func mapKey(k string, t reflect.Type) (reflect.Value, error) {
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		kv := reflect.New(t)
+		if err := kv.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(k)); err != nil {
+			return reflect.Value{}, err
+		}
+		return kv.Elem(), nil
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return reflect.ValueOf(k).Convert(t), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(k, 10, 64)
+		if err != nil || reflect.Zero(t).OverflowInt(n) {
+			return reflect.Value{}, fmt.Errorf("cannot decode key %q into %s", k, t)
+		}
+		return reflect.ValueOf(n).Convert(t), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(k, 10, 64)
+		if err != nil || reflect.Zero(t).OverflowUint(n) {
+			return reflect.Value{}, fmt.Errorf("cannot decode key %q into %s", k, t)
+		}
+		return reflect.ValueOf(n).Convert(t), nil
+	default:
+		return reflect.Value{}, fmt.Errorf("cannot decode object into map with %s keys", t)
+	}
+}
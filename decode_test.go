@@ -0,0 +1,113 @@
+package goson
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func parseValue(t *testing.T, s string) *Value {
+	var p Parser
+	v, err := p.Parse(s)
+	assert.Nil(t, err)
+	return v
+}
+
+func stringify(t *testing.T, v *Value) string {
+	s, err := v.stringifyValue()
+	assert.Nil(t, err)
+	return s
+}
+
+func TestAs(t *testing.T) {
+	i, err := As[int](parseValue(t, "42"))
+	assert.Nil(t, err)
+	assert.Equal(t, 42, i)
+
+	u, err := As[uint8](parseValue(t, "255"))
+	assert.Nil(t, err)
+	assert.Equal(t, uint8(255), u)
+
+	f, err := As[float32](parseValue(t, "1.5"))
+	assert.Nil(t, err)
+	assert.Equal(t, float32(1.5), f)
+
+	b, err := As[bool](parseValue(t, "true"))
+	assert.Nil(t, err)
+	assert.Equal(t, true, b)
+
+	ss, err := As[[]string](parseValue(t, "[\"a\",\"b\"]"))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b"}, ss)
+
+	m, err := As[map[string]float64](parseValue(t, "{\"x\":1,\"y\":2.5,\"x\":3}"))
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]float64{"x": 1, "y": 2.5}, m)
+
+	a, err := As[[2][]int](parseValue(t, "[[1],[]]"))
+	assert.Nil(t, err)
+	assert.Equal(t, [2][]int{{1}, {}}, a)
+
+	p, err := As[*int](parseValue(t, "null"))
+	assert.Nil(t, err)
+	assert.Nil(t, p)
+	p, err = As[*int](parseValue(t, "7"))
+	assert.Nil(t, err)
+	assert.Equal(t, 7, *p)
+
+	bs, err := As[[]byte](parseValue(t, "\"aGVsbG8=\""))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("hello"), bs)
+
+	x, err := As[any](parseValue(t, "{\"a\":[1,\"b\",null,true]}"))
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]any{"a": []any{1.0, "b", nil, true}}, x)
+}
+
+func TestAsError(t *testing.T) {
+	_, err := As[int](parseValue(t, "1.5"))
+	assert.EqualError(t, err, "cannot decode 1.5 into int: not an integer")
+	_, err = As[int8](parseValue(t, "300"))
+	assert.EqualError(t, err, "cannot decode 300 into int8: out of range")
+	_, err = As[uint](parseValue(t, "-1"))
+	assert.EqualError(t, err, "cannot decode -1 into uint: out of range")
+	_, err = As[int64](parseValue(t, "1e19"))
+	assert.EqualError(t, err, "cannot decode 1e+19 into int64: out of range")
+	_, err = As[float32](parseValue(t, "1e300"))
+	assert.EqualError(t, err, "cannot decode 1e+300 into float32: out of range")
+	n, err := As[int](parseValue(t, "null"))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, n)
+	_, err = As[[1]int](parseValue(t, "[1,2]"))
+	assert.EqualError(t, err, "cannot decode array of 2 elements into [1]int")
+	_, err = As[map[string][]int](parseValue(t, "{\"a\":[1],\"b/c\":[1,\"x\"]}"))
+	assert.EqualError(t, err, "/b~1c/1: cannot decode string into int")
+	_, err = As[chan int](parseValue(t, "1"))
+	assert.EqualError(t, err, "cannot decode into unsupported type chan int")
+
+	var de *DecodeError
+	_, err = As[[]int](parseValue(t, "[1,true]"))
+	assert.ErrorAs(t, err, &de)
+	assert.Equal(t, Path{1}, de.Path)
+}
+
+func TestGetAs(t *testing.T) {
+	v := parseValue(t, "{\"port\":8080,\"hosts\":[\"a\",\"b\"]}")
+	port, err := GetAs[uint16](v, "port")
+	assert.Nil(t, err)
+	assert.Equal(t, uint16(8080), port)
+	hosts, err := GetAs[[]string](v, "hosts")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b"}, hosts)
+
+	_, err = GetAs[int](v, "missing")
+	assert.ErrorIs(t, err, ErrKeyNotExist)
+	assert.EqualError(t, err, "\"\": segment \"missing\": key not exist")
+	_, err = GetAs[int](parseValue(t, "[1]"), "port")
+	assert.ErrorIs(t, err, ErrPathTypeMismatch)
+	var le *LookupError
+	assert.ErrorAs(t, err, &le)
+	assert.Equal(t, Path{}, le.Path)
+	assert.Equal(t, "port", le.Segment)
+	_, err = GetAs[[]int](v, "hosts")
+	assert.EqualError(t, err, "/hosts/0: cannot decode string into int")
+}
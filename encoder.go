@@ -0,0 +1,243 @@
+package goson
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// NonFinitePolicy decides how NaN and infinite numbers, which JSON cannot
+// represent, are written.
+type NonFinitePolicy int
+
+const (
+	NonFiniteError NonFinitePolicy = iota
+	NonFiniteNull
+	NonFiniteString
+	NonFiniteLiteral
+)
+
+func formatNumber(n float64, policy NonFinitePolicy) (string, error) {
+	if !math.IsNaN(n) && !math.IsInf(n, 0) {
+		return strconv.FormatFloat(n, 'g', 17, 64), nil
+	}
+	var s string
+	switch {
+	case math.IsNaN(n):
+		s = "NaN"
+	case n > 0:
+		s = "Infinity"
+	default:
+		s = "-Infinity"
+	}
+	switch policy {
+	case NonFiniteNull:
+		return "null", nil
+	case NonFiniteString:
+		return "\"" + s + "\"", nil
+	case NonFiniteLiteral:
+		return s, nil
+	default:
+		return "", ErrNumberNotFinite
+	}
+}
+
+// Encoder renders a Value as JSON text. With an empty Indent the output is
+// compact, exactly like stringifyValue. With an Indent and no Width every
+// non-empty array and object is broken over several lines. With both set,
+// a container stays on one line when it fits within Width columns and is
+// broken otherwise. A non-nil Theme wraps every token in ANSI colors,
+// unless the NO_COLOR environment variable is set.
+// NonFinite picks what happens to NaN and infinities; by default they fail
+// the encoding with ErrNumberNotFinite.
+type Encoder struct {
+	Indent    string
+	Width     int
+	Theme     *Theme
+	NonFinite NonFinitePolicy
+}
+
+func (e *Encoder) Encode(v *Value) (string, error) {
+	var b strings.Builder
+	if err := e.encodeValue(&b, e.theme(), v, 0, 0, 0); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func (e *Encoder) scalar(v *Value) (string, error) {
+	if v.t == NUMBER {
+		return formatNumber(v.num(), e.NonFinite)
+	}
+	return v.stringifyValue()
+}
+
+// theme picks the colors for one call of Encode, which passes them down.
+func (e *Encoder) theme() *Theme {
+	if e.Theme == nil || noColor() {
+		return &plainTheme
+	}
+	return e.Theme
+}
+
+func (e *Encoder) paint(b *strings.Builder, style, s string) {
+	if style == "" {
+		b.WriteString(s)
+		return
+	}
+	b.WriteString("\x1b[")
+	b.WriteString(style)
+	b.WriteByte('m')
+	b.WriteString(s)
+	b.WriteString("\x1b[0m")
+}
+
+func (e *Encoder) newline(b *strings.Builder, depth int) {
+	b.WriteByte('\n')
+	for i := 0; i < depth; i++ {
+		b.WriteString(e.Indent)
+	}
+}
+
+func (e *Encoder) column(depth int) int {
+	return depth * utf8.RuneCountInString(e.Indent)
+}
+
+func (e *Encoder) separators() (string, string) {
+	if e.Indent == "" {
+		return ",", ":"
+	}
+	return ", ", ": "
+}
+
+func (e *Encoder) fits(v *Value, col, trail int) bool {
+	if e.Indent == "" {
+		return true
+	}
+	if e.Width <= 0 {
+		return false
+	}
+	return e.flatWidth(v, e.Width-col-trail) >= 0
+}
+
+func (e *Encoder) flatWidth(v *Value, budget int) int {
+	comma, colon := e.separators()
+	switch v.t {
+	case ARRAY:
+		budget -= 2
+		for i, elem := range v.arr() {
+			if budget < 0 {
+				return budget
+			}
+			if i > 0 {
+				budget -= len(comma)
+			}
+			budget = e.flatWidth(elem, budget)
+		}
+	case OBJECT:
+		budget -= 2
+		for i, kv := range v.obj() {
+			if budget < 0 {
+				return budget
+			}
+			if i > 0 {
+				budget -= len(comma)
+			}
+			budget -= utf8.RuneCountInString(stringifyString(kv.k)) + len(colon)
+			budget = e.flatWidth(kv.v, budget)
+		}
+	default:
+		s, _ := e.scalar(v)
+		budget -= utf8.RuneCountInString(s)
+	}
+	return budget
+}
+
+func (e *Encoder) encodeFlat(b *strings.Builder, t *Theme, v *Value) error {
+	comma, colon := e.separators()
+	switch v.t {
+	case ARRAY:
+		e.paint(b, t.Punct, "[")
+		for i, elem := range v.arr() {
+			if i > 0 {
+				e.paint(b, t.Punct, comma)
+			}
+			if err := e.encodeFlat(b, t, elem); err != nil {
+				return err
+			}
+		}
+		e.paint(b, t.Punct, "]")
+	case OBJECT:
+		e.paint(b, t.Punct, "{")
+		for i, kv := range v.obj() {
+			if i > 0 {
+				e.paint(b, t.Punct, comma)
+			}
+			e.paint(b, t.Key, stringifyString(kv.k))
+			e.paint(b, t.Punct, colon)
+			if err := e.encodeFlat(b, t, kv.v); err != nil {
+				return err
+			}
+		}
+		e.paint(b, t.Punct, "}")
+	default:
+		s, err := e.scalar(v)
+		if err != nil {
+			return err
+		}
+		e.paint(b, t.scalar(v.t), s)
+	}
+	return nil
+}
+
+func (e *Encoder) encodeValue(b *strings.Builder, t *Theme, v *Value, depth, col, trail int) error {
+	switch v.t {
+	case ARRAY:
+		if len(v.arr()) == 0 || e.fits(v, col, trail) {
+			return e.encodeFlat(b, t, v)
+		}
+		e.paint(b, t.Punct, "[")
+		for i, elem := range v.arr() {
+			if i > 0 {
+				e.paint(b, t.Punct, ",")
+			}
+			e.newline(b, depth+1)
+			if err := e.encodeValue(b, t, elem, depth+1, e.column(depth+1), trailing(i, len(v.arr()))); err != nil {
+				return err
+			}
+		}
+		e.newline(b, depth)
+		e.paint(b, t.Punct, "]")
+	case OBJECT:
+		if len(v.obj()) == 0 || e.fits(v, col, trail) {
+			return e.encodeFlat(b, t, v)
+		}
+		e.paint(b, t.Punct, "{")
+		for i, kv := range v.obj() {
+			if i > 0 {
+				e.paint(b, t.Punct, ",")
+			}
+			e.newline(b, depth+1)
+			key := stringifyString(kv.k)
+			e.paint(b, t.Key, key)
+			e.paint(b, t.Punct, ": ")
+			col := e.column(depth+1) + utf8.RuneCountInString(key) + 2
+			if err := e.encodeValue(b, t, kv.v, depth+1, col, trailing(i, len(v.obj()))); err != nil {
+				return err
+			}
+		}
+		e.newline(b, depth)
+		e.paint(b, t.Punct, "}")
+	default:
+		return e.encodeFlat(b, t, v)
+	}
+	return nil
+}
+
+func trailing(i, n int) int {
+	if i < n-1 {
+		return 1
+	}
+	return 0
+}
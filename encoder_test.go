@@ -0,0 +1,83 @@
+package goson
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func encodeString(t *testing.T, e Encoder, s string) string {
+	var p Parser
+	v, err := p.Parse(s)
+	assert.Nil(t, err)
+	s, err = e.Encode(v)
+	assert.Nil(t, err)
+	return s
+}
+
+func TestEncodeCompact(t *testing.T) {
+	var e Encoder
+	s := "{\"n\":null,\"f\":false,\"t\":true,\"i\":123,\"s\":\"abc\",\"a\":[1,2,3],\"o\":{\"1\":1,\"2\":2,\"3\":3}}"
+	assert.Equal(t, s, encodeString(t, e, s))
+	assert.Equal(t, "[]", encodeString(t, e, "[ ]"))
+}
+
+func TestEncodeIndent(t *testing.T) {
+	e := Encoder{Indent: "  "}
+	assert.Equal(t, "[]", encodeString(t, e, "[]"))
+	assert.Equal(t, "{}", encodeString(t, e, "{}"))
+	assert.Equal(t, "[\n  1,\n  2\n]", encodeString(t, e, "[1,2]"))
+	assert.Equal(t, "{\n  \"a\": [\n    1\n  ],\n  \"b\": {}\n}", encodeString(t, e, "{\"a\":[1],\"b\":{}}"))
+}
+
+func TestEncodeWidth(t *testing.T) {
+	e := Encoder{Indent: "  ", Width: 20}
+	assert.Equal(t, "[1, 2, 3]", encodeString(t, e, "[1,2,3]"))
+	assert.Equal(t, "{\"a\": [1, 2, 3]}", encodeString(t, e, "{\"a\":[1,2,3]}"))
+	assert.Equal(t, "{\n  \"abc\": [1, 2, 3],\n  \"d\": \"efghij\"\n}",
+		encodeString(t, e, "{\"abc\":[1,2,3],\"d\":\"efghij\"}"))
+	assert.Equal(t, "[\n  \"0123456789\",\n  \"0123456789\"\n]",
+		encodeString(t, e, "[\"0123456789\",\"0123456789\"]"))
+
+	e.Width = 10
+	assert.Equal(t, "{\n  \"abc\": [\n    1,\n    2,\n    3\n  ],\n  \"d\": 4\n}",
+		encodeString(t, e, "{\"abc\":[1,2,3],\"d\":4}"))
+}
+
+func TestEncodeStable(t *testing.T) {
+	s := "{\"name\":\"goson\",\"tags\":[\"json\",\"parser\",\"go\"],\"nested\":{\"matrix\":[[1,2,3],[4,5,6],[7,8,9]],\"empty\":[],\"deep\":{\"a\":{\"b\":{\"c\":\"a long string value here\"}}}}}"
+	for _, width := range []int{0, 10, 20, 40, 80} {
+		e := Encoder{Indent: "\t", Width: width}
+		once := encodeString(t, e, s)
+		twice := encodeString(t, e, once)
+		assert.Equal(t, once, twice)
+		assert.Equal(t, s, encodeString(t, Encoder{}, once))
+	}
+}
+
+func TestEncodeNonFinite(t *testing.T) {
+	f := func(policy NonFinitePolicy, n float64, s string, err error) {
+		var v Value
+		v.setArray(1)
+		var e Value
+		e.setNumber(n)
+		_ = v.insertArrayElement(&e, 0)
+		enc := Encoder{NonFinite: policy}
+		ss, ee := enc.Encode(&v)
+		assert.Equal(t, err, ee)
+		assert.Equal(t, s, ss)
+	}
+	f(NonFiniteError, math.NaN(), "", ErrNumberNotFinite)
+	f(NonFiniteError, math.Inf(1), "", ErrNumberNotFinite)
+	f(NonFiniteError, 1.5, "[1.5]", nil)
+	f(NonFiniteNull, math.NaN(), "[null]", nil)
+	f(NonFiniteNull, math.Inf(-1), "[null]", nil)
+	f(NonFiniteString, math.NaN(), "[\"NaN\"]", nil)
+	f(NonFiniteString, math.Inf(1), "[\"Infinity\"]", nil)
+	f(NonFiniteLiteral, math.Inf(-1), "[-Infinity]", nil)
+
+	_, err := NewArray(NewNumber(math.NaN())).stringifyValue()
+	assert.Equal(t, ErrNumberNotFinite, err)
+	assert.Equal(t, "[NaN,{\"a\":-Infinity}]",
+		Preview(NewArray(NewNumber(math.NaN()), NewObject(NewKV("a", NewNumber(math.Inf(-1))))), PreviewOptions{}))
+}
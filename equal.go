@@ -0,0 +1,242 @@
+package goson
+
+import "math"
+
+// EqualOptions relaxes the comparison made by Equal. The zero value asks
+// for exact structural equality.
+//
+// NumberTolerance lets two numbers differ by up to that amount.
+// IgnoreArrayOrder compares arrays as multisets. IgnorePaths lists JSON
+// Pointers, as in MergeOptions.Paths with "*" matching any segment, whose
+// nodes are not compared at all; the paths are taken from the left-hand
+// value. MissingAsNull treats an absent object member as equal to one
+// holding null.
+type EqualOptions struct {
+	NumberTolerance  float64
+	IgnoreArrayOrder bool
+	IgnorePaths      []string
+	MissingAsNull    bool
+}
+
+// Equal reports whether a and b hold the same JSON data. Object members
+// are matched by key regardless of their order; when a key repeats, the
+// first member counts, so both objects must have the same set of distinct
+// keys. NaN equals NaN, so that Equal agrees with Compare.
+func Equal(a, b *Value, opts EqualOptions) bool {
+	eq := equaler{opts: opts}
+	for _, pointer := range opts.IgnorePaths {
+		eq.ignore = append(eq.ignore, splitPointer(pointer))
+	}
+	return eq.equal(a, b, nil)
+}
+
+type equaler struct {
+	opts   EqualOptions
+	ignore [][]string
+}
+
+func (eq *equaler) ignored(path Path) bool {
+	for _, segments := range eq.ignore {
+		if matchPointer(segments, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// child extends path only when some path is ignored, so that the common
+// case does not allocate.
+func (eq *equaler) child(path Path, seg any) Path {
+	if len(eq.ignore) == 0 {
+		return nil
+	}
+	return path.append(seg)
+}
+
+func (eq *equaler) equal(a, b *Value, path Path) bool {
+	if len(eq.ignore) != 0 && eq.ignored(path) {
+		return true
+	}
+	if a.t != b.t {
+		return false
+	}
+	switch a.t {
+	case STRING:
+		return a.str() == b.str()
+	case NUMBER:
+		an, bn := a.num(), b.num()
+		if math.IsNaN(an) || math.IsNaN(bn) {
+			return math.IsNaN(an) && math.IsNaN(bn)
+		}
+		return an == bn || math.Abs(an-bn) <= eq.opts.NumberTolerance
+	case ARRAY:
+		aa, ba := a.arr(), b.arr()
+		if len(aa) != len(ba) {
+			return false
+		}
+		if eq.opts.IgnoreArrayOrder {
+			return eq.unordered(a, b, path)
+		}
+		for i := range aa {
+			if !eq.equal(aa[i], ba[i], eq.child(path, i)) {
+				return false
+			}
+		}
+		return true
+	case OBJECT:
+		return eq.object(a, b, path)
+	default:
+		return true
+	}
+}
+
+// unordered compares two arrays of the same length as multisets. When
+// equality is exact it is an equivalence, so the elements of b are
+// bucketed by hash and each element of a takes the first equal one left
+// in its bucket. A tolerance or an ignored path breaks transitivity, and
+// then a first come match can block a pairing that works, so the arrays
+// are compared as a bipartite matching instead.
+func (eq *equaler) unordered(a, b *Value, path Path) bool {
+	if eq.opts.NumberTolerance == 0 && len(eq.ignore) == 0 && !eq.opts.MissingAsNull {
+		return eq.bucketed(a, b, path)
+	}
+	return eq.matched(a, b, path)
+}
+
+func (eq *equaler) bucketed(a, b *Value, path Path) bool {
+	buckets := make(map[uint64][]*Value, len(b.arr()))
+	for _, y := range b.arr() {
+		h := hashValue(y, true)
+		buckets[h] = append(buckets[h], y)
+	}
+	for i, x := range a.arr() {
+		h := hashValue(x, true)
+		bucket := buckets[h]
+		j := 0
+		for j < len(bucket) && !eq.equal(x, bucket[j], eq.child(path, i)) {
+			j++
+		}
+		if j == len(bucket) {
+			return false
+		}
+		bucket[j] = bucket[len(bucket)-1]
+		buckets[h] = bucket[:len(bucket)-1]
+	}
+	return true
+}
+
+// matched reports whether every element of a can be paired with a
+// distinct equal element of b, using augmenting paths (Kuhn's algorithm).
+func (eq *equaler) matched(a, b *Value, path Path) bool {
+	aa, ba := a.arr(), b.arr()
+	edges := make([][]int, len(aa))
+	for i, x := range aa {
+		for j, y := range ba {
+			if eq.equal(x, y, eq.child(path, i)) {
+				edges[i] = append(edges[i], j)
+			}
+		}
+		if len(edges[i]) == 0 {
+			return false
+		}
+	}
+
+	match := make([]int, len(ba))
+	for j := range match {
+		match[j] = -1
+	}
+	seen := make([]bool, len(ba))
+	var augment func(i int) bool
+	augment = func(i int) bool {
+		for _, j := range edges[i] {
+			if seen[j] {
+				continue
+			}
+			seen[j] = true
+			if match[j] < 0 || augment(match[j]) {
+				match[j] = i
+				return true
+			}
+		}
+		return false
+	}
+	for i := range aa {
+		for j := range seen {
+			seen[j] = false
+		}
+		if !augment(i) {
+			return false
+		}
+	}
+	return true
+}
+
+func (eq *equaler) object(a, b *Value, path Path) bool {
+	am, bm := memberIndex(a), memberIndex(b)
+	for i, kv := range a.obj() {
+		if firstMember(a, am, kv.k) != i {
+			continue
+		}
+		p := eq.child(path, kv.k)
+		j := firstMember(b, bm, kv.k)
+		if j < 0 {
+			if !eq.missing(kv.v, p) {
+				return false
+			}
+			continue
+		}
+		if !eq.equal(kv.v, b.obj()[j].v, p) {
+			return false
+		}
+	}
+	for j, kv := range b.obj() {
+		if firstMember(b, bm, kv.k) != j || firstMember(a, am, kv.k) >= 0 {
+			continue
+		}
+		if !eq.missing(kv.v, eq.child(path, kv.k)) {
+			return false
+		}
+	}
+	return true
+}
+
+// missing reports whether a member present on one side only, holding v,
+// may be absent from the other.
+func (eq *equaler) missing(v *Value, path Path) bool {
+	return (eq.opts.MissingAsNull && v.t == NULL) || (len(eq.ignore) != 0 && eq.ignored(path))
+}
+
+// memberIndex returns a key to position map for objects large enough to
+// use one, reusing the object's own index when it has been built. It
+// never builds the index on v itself, so comparing shared values does not
+// write to them.
+func memberIndex(v *Value) map[string]int {
+	if len(v.obj()) < objectIndexThreshold {
+		return nil
+	}
+	if idx := v.index(); idx != nil {
+		return idx
+	}
+	m := make(map[string]int, len(v.obj()))
+	for i, kv := range v.obj() {
+		if _, ok := m[kv.k]; !ok {
+			m[kv.k] = i
+		}
+	}
+	return m
+}
+
+func firstMember(v *Value, m map[string]int, key string) int {
+	if m != nil {
+		if i, ok := m[key]; ok {
+			return i
+		}
+		return -1
+	}
+	for i, kv := range v.obj() {
+		if kv.k == key {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,80 @@
+package goson
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	f := func(opts EqualOptions, l, r string, want bool) {
+		assert.Equal(t, want, Equal(parseValue(t, l), parseValue(t, r), opts), l+" vs "+r)
+		assert.Equal(t, want, Equal(parseValue(t, r), parseValue(t, l), opts), r+" vs "+l)
+	}
+	var exact EqualOptions
+	f(exact, "{\"a\":1,\"b\":[true,null]}", "{\"b\":[true,null],\"a\":1}", true)
+	f(exact, "[1,2]", "[2,1]", false)
+	f(exact, "{\"a\":1,\"a\":2}", "{\"a\":1,\"b\":2}", false)
+	f(exact, "{\"a\":1,\"a\":2}", "{\"a\":1}", true)
+	f(exact, "{\"a\":1,\"a\":2}", "{\"a\":2}", false)
+	f(exact, "{\"a\":null}", "{}", false)
+
+	f(EqualOptions{NumberTolerance: 0.01}, "[1.005]", "[1]", true)
+	f(EqualOptions{NumberTolerance: 0.01}, "[1.02]", "[1]", false)
+	nan, inf := NewNumber(math.NaN()), NewNumber(math.Inf(1))
+	assert.True(t, Equal(NewArray(nan), NewArray(nan.copy()), exact))
+	assert.False(t, Equal(nan, inf, EqualOptions{NumberTolerance: math.Inf(1)}))
+	assert.Equal(t, 0, Compare(nan, nan.copy()))
+
+	unordered := EqualOptions{IgnoreArrayOrder: true}
+	f(unordered, "[1,2,[3,4]]", "[[4,3],2,1]", true)
+	f(unordered, "[1,1,2]", "[1,2,2]", false)
+	f(unordered, "[{\"a\":[1,2]},{\"a\":[2]}]", "[{\"a\":[2]},{\"a\":[2,1]}]", true)
+	f(EqualOptions{IgnoreArrayOrder: true, NumberTolerance: 0.1}, "[1.05,1.0]", "[1.0,1.12]", true)
+	f(EqualOptions{IgnoreArrayOrder: true, NumberTolerance: 0.1}, "[1.05,1.0]", "[1.2,1.12]", false)
+	assert.True(t, Equal(parseValue(t, "[{\"id\":1,\"n\":1},{\"id\":2,\"n\":1}]"),
+		parseValue(t, "[{\"id\":2,\"n\":1},{\"n\":1}]"),
+		EqualOptions{IgnoreArrayOrder: true, IgnorePaths: []string{"/0/id"}}))
+
+	f(EqualOptions{MissingAsNull: true}, "{\"a\":null,\"b\":1}", "{\"b\":1}", true)
+	f(EqualOptions{MissingAsNull: true}, "{\"a\":0}", "{}", false)
+
+	ignore := EqualOptions{IgnorePaths: []string{"/meta/updated", "/items/*/id"}}
+	f(ignore, "{\"meta\":{\"updated\":1,\"v\":2},\"items\":[{\"id\":1,\"n\":\"x\"}]}",
+		"{\"meta\":{\"v\":2},\"items\":[{\"id\":7,\"n\":\"x\"}]}", true)
+	f(ignore, "{\"meta\":{\"v\":2},\"items\":[{\"n\":\"x\"}]}", "{\"meta\":{\"v\":3},\"items\":[{\"n\":\"x\"}]}", false)
+}
+
+func TestEqualLargeObject(t *testing.T) {
+	l, r := NewObject(), NewObject()
+	for i := 0; i < 2*objectIndexThreshold; i++ {
+		_ = l.setObjectValue("k"+strconv.Itoa(i), NewNumber(float64(i)))
+	}
+	for i := 2*objectIndexThreshold - 1; i >= 0; i-- {
+		_ = r.setObjectValue("k"+strconv.Itoa(i), NewNumber(float64(i)))
+	}
+	l.setIndex(nil)
+	r.setIndex(nil)
+	assert.True(t, Equal(l, r, EqualOptions{}))
+	assert.Nil(t, l.index())
+	assert.Nil(t, r.index())
+
+	_ = r.setObjectValue("k3", NewNumber(-1))
+	assert.False(t, Equal(l, r, EqualOptions{}))
+}
+
+func BenchmarkEqualUnordered(b *testing.B) {
+	l, r := NewArray(), NewArray()
+	for i := 0; i < 10000; i++ {
+		_ = l.insertArrayElement(NewNumber(float64(i)), i)
+		_ = r.insertArrayElement(NewNumber(float64(9999-i)), i)
+	}
+	opts := EqualOptions{IgnoreArrayOrder: true}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !Equal(l, r, opts) {
+			b.Fatal("not equal")
+		}
+	}
+}
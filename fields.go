@@ -0,0 +1,143 @@
+package goson
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type field struct {
+	name      string
+	index     []int
+	typ       reflect.Type
+	tagged    bool
+	omitEmpty bool
+	quoted    bool
+}
+
+var fieldCache sync.Map
+
+// cachedFields lists the JSON members of struct type t following the
+// encoding/json rules: json tags rename or skip fields, and the fields of
+// untagged embedded structs are promoted unless a shallower field or an
+// equally deep tagged one has the same name.
+func cachedFields(t reflect.Type) []field {
+	if fs, ok := fieldCache.Load(t); ok {
+		return fs.([]field)
+	}
+	fs, _ := fieldCache.LoadOrStore(t, typeFields(t))
+	return fs.([]field)
+}
+
+func typeFields(t reflect.Type) []field {
+	type queued struct {
+		typ   reflect.Type
+		index []int
+	}
+	var fields []field
+	depth := map[string]int{}
+	visited := map[reflect.Type]bool{}
+	current := []queued{{t, nil}}
+
+	for level := 0; len(current) > 0; level++ {
+		var next []queued
+		var found []field
+		for _, q := range current {
+			if visited[q.typ] {
+				continue
+			}
+			visited[q.typ] = true
+			for i := 0; i < q.typ.NumField(); i++ {
+				sf := q.typ.Field(i)
+				ft := sf.Type
+				if sf.Anonymous {
+					if ft.Kind() == reflect.Pointer {
+						ft = ft.Elem()
+					}
+					if !sf.IsExported() && ft.Kind() != reflect.Struct {
+						continue
+					}
+				} else if !sf.IsExported() {
+					continue
+				}
+
+				tag := sf.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				name, opts, _ := strings.Cut(tag, ",")
+				index := make([]int, len(q.index)+1)
+				copy(index, q.index)
+				index[len(q.index)] = i
+
+				if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+					next = append(next, queued{ft, index})
+					continue
+				}
+				f := field{
+					name:   name,
+					index:  index,
+					typ:    sf.Type,
+					tagged: name != "",
+				}
+				if f.name == "" {
+					f.name = sf.Name
+				}
+				for opts != "" {
+					var opt string
+					opt, opts, _ = strings.Cut(opts, ",")
+					switch opt {
+					case "omitempty":
+						f.omitEmpty = true
+					case "string":
+						f.quoted = true
+					}
+				}
+				found = append(found, f)
+			}
+		}
+
+		byName := map[string][]field{}
+		for _, f := range found {
+			byName[f.name] = append(byName[f.name], f)
+		}
+		for name, fs := range byName {
+			if _, ok := depth[name]; ok {
+				continue
+			}
+			depth[name] = level
+			if f, ok := dominant(fs); ok {
+				fields = append(fields, f)
+			}
+		}
+		current = next
+	}
+
+	sort.Slice(fields, func(i, j int) bool {
+		a, b := fields[i].index, fields[j].index
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+	return fields
+}
+
+func dominant(fs []field) (field, bool) {
+	if len(fs) == 1 {
+		return fs[0], true
+	}
+	var tagged []field
+	for _, f := range fs {
+		if f.tagged {
+			tagged = append(tagged, f)
+		}
+	}
+	if len(tagged) == 1 {
+		return tagged[0], true
+	}
+	return field{}, false
+}
@@ -0,0 +1,418 @@
+package goson
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strconv"
+)
+
+// Compact copies the JSON text read from src to dst with insignificant
+// white space removed. The text is validated with the same rules as
+// Parser.Parse, but no Value tree is built, so memory use does not grow
+// with the size of the input. Number and string literals are copied byte
+// for byte. On error, dst may already hold part of the output.
+func Compact(dst io.Writer, src io.Reader) error {
+	f := reformatter{r: bufio.NewReader(src), w: bufio.NewWriter(dst)}
+	return f.reformat()
+}
+
+// Indent is like Compact, but puts every array element and object member
+// on a new line that starts with prefix followed by one copy of indent per
+// level of nesting.
+func Indent(dst io.Writer, src io.Reader, prefix, indent string) error {
+	f := reformatter{r: bufio.NewReader(src), w: bufio.NewWriter(dst), prefix: prefix, indent: indent, pretty: true}
+	return f.reformat()
+}
+
+type reformatter struct {
+	r      *bufio.Reader
+	w      *bufio.Writer
+	prefix string
+	indent string
+	pretty bool
+	depth  int
+	num    []byte
+}
+
+func (f *reformatter) reformat() error {
+	if err := f.skipWhiteSpace(); err != nil {
+		return err
+	}
+	if err := f.value(); err != nil {
+		return err
+	}
+	if err := f.skipWhiteSpace(); err != nil {
+		return err
+	}
+	if _, ok, err := f.peek(); err != nil {
+		return err
+	} else if ok {
+		return ErrParseRootNotSingular
+	}
+	return f.w.Flush()
+}
+
+func (f *reformatter) peek() (byte, bool, error) {
+	b, err := f.r.Peek(1)
+	if errors.Is(err, io.EOF) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return b[0], true, nil
+}
+
+func (f *reformatter) next() (byte, bool, error) {
+	ch, ok, err := f.peek()
+	if ok {
+		_, _ = f.r.ReadByte()
+	}
+	return ch, ok, err
+}
+
+func (f *reformatter) skipWhiteSpace() error {
+	for {
+		ch, ok, err := f.peek()
+		if err != nil || !ok {
+			return err
+		}
+		if ch != ' ' && ch != '\t' && ch != '\n' && ch != '\r' {
+			return nil
+		}
+		_, _ = f.r.ReadByte()
+	}
+}
+
+func (f *reformatter) newline() {
+	if !f.pretty {
+		return
+	}
+	f.w.WriteByte('\n')
+	f.w.WriteString(f.prefix)
+	for i := 0; i < f.depth; i++ {
+		f.w.WriteString(f.indent)
+	}
+}
+
+func (f *reformatter) value() error {
+	ch, ok, err := f.peek()
+	if err != nil || !ok {
+		return err
+	}
+	switch ch {
+	case 't':
+		return f.literal("true")
+	case 'f':
+		return f.literal("false")
+	case 'n':
+		return f.literal("null")
+	case '"':
+		return f.string()
+	case '[':
+		return f.array()
+	case '{':
+		return f.object()
+	default:
+		return f.number()
+	}
+}
+
+func (f *reformatter) literal(json string) error {
+	for i := 0; i < len(json); i++ {
+		ch, ok, err := f.next()
+		if err != nil {
+			return err
+		}
+		if !ok || ch != json[i] {
+			return ErrParseInvalidValue
+		}
+	}
+	f.w.WriteString(json)
+	return nil
+}
+
+func (f *reformatter) digits() error {
+	for {
+		ch, ok, err := f.peek()
+		if err != nil || !ok || !isDigit(ch) {
+			return err
+		}
+		f.num = append(f.num, ch)
+		_, _ = f.r.ReadByte()
+	}
+}
+
+func (f *reformatter) accept(match func(byte) bool) (bool, error) {
+	ch, ok, err := f.peek()
+	if err != nil || !ok || !match(ch) {
+		return false, err
+	}
+	f.num = append(f.num, ch)
+	_, _ = f.r.ReadByte()
+	return true, nil
+}
+
+func (f *reformatter) number() error {
+	f.num = f.num[:0]
+
+	if _, err := f.accept(func(b byte) bool { return b == '-' }); err != nil {
+		return err
+	}
+	if ok, err := f.accept(func(b byte) bool { return b == '0' }); err != nil {
+		return err
+	} else if !ok {
+		if ok, err = f.accept(isDigit1To9); err != nil {
+			return err
+		} else if !ok {
+			return ErrParseInvalidValue
+		}
+		if err = f.digits(); err != nil {
+			return err
+		}
+	}
+	if ok, err := f.accept(func(b byte) bool { return b == '.' }); err != nil {
+		return err
+	} else if ok {
+		if ok, err = f.accept(isDigit); err != nil {
+			return err
+		} else if !ok {
+			return ErrParseInvalidValue
+		}
+		if err = f.digits(); err != nil {
+			return err
+		}
+	}
+	if ok, err := f.accept(func(b byte) bool { return b == 'e' || b == 'E' }); err != nil {
+		return err
+	} else if ok {
+		if _, err = f.accept(func(b byte) bool { return b == '+' || b == '-' }); err != nil {
+			return err
+		}
+		if ok, err = f.accept(isDigit); err != nil {
+			return err
+		} else if !ok {
+			return ErrParseInvalidValue
+		}
+		if err = f.digits(); err != nil {
+			return err
+		}
+	}
+
+	if _, err := strconv.ParseFloat(string(f.num), 64); err != nil && errors.Is(err, strconv.ErrRange) {
+		return ErrParseNumberTooBig
+	}
+	f.w.Write(f.num)
+	return nil
+}
+
+func (f *reformatter) hex4() (uint32, error) {
+	var u uint32
+	for i := 0; i < 4; i++ {
+		ch, ok, err := f.next()
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			return 0, ErrParseInvalidUnicodeHex
+		}
+		f.w.WriteByte(ch)
+		u <<= 4
+		switch {
+		case ch >= '0' && ch <= '9':
+			u |= uint32(ch - '0')
+		case ch >= 'A' && ch <= 'F':
+			u |= uint32(ch - 'A' + 10)
+		case ch >= 'a' && ch <= 'f':
+			u |= uint32(ch - 'a' + 10)
+		default:
+			return 0, ErrParseInvalidUnicodeHex
+		}
+	}
+	return u, nil
+}
+
+func (f *reformatter) string() error {
+	_, _, _ = f.next()
+	f.w.WriteByte('"')
+	for {
+		ch, ok, err := f.next()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrParseMissQuotationMark
+		}
+		f.w.WriteByte(ch)
+
+		switch ch {
+		case '"':
+			return nil
+		case '\\':
+			ch, ok, err = f.next()
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return ErrParseMissQuotationMark
+			}
+			f.w.WriteByte(ch)
+			switch ch {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+			case 'u':
+				u, err := f.hex4()
+				if err != nil {
+					return err
+				}
+				if u >= 0xD800 && u <= 0xDBFF {
+					if err = f.lowSurrogate(); err != nil {
+						return err
+					}
+				}
+			default:
+				return ErrParseInvalidStringEscape
+			}
+		default:
+			if ch < 0x20 {
+				return ErrParseInvalidStringChar
+			}
+		}
+	}
+}
+
+func (f *reformatter) lowSurrogate() error {
+	for _, want := range []byte{'\\', 'u'} {
+		ch, ok, err := f.next()
+		if err != nil {
+			return err
+		}
+		if !ok || ch != want {
+			return ErrParseInvalidUnicodeSurrogate
+		}
+		f.w.WriteByte(ch)
+	}
+	u, err := f.hex4()
+	if err != nil {
+		if errors.Is(err, ErrParseInvalidUnicodeHex) {
+			return ErrParseInvalidUnicodeSurrogate
+		}
+		return err
+	}
+	if !(u >= 0xDC00 && u <= 0xDFFF) {
+		return ErrParseInvalidUnicodeSurrogate
+	}
+	return nil
+}
+
+func (f *reformatter) array() error {
+	_, _, _ = f.next()
+	f.w.WriteByte('[')
+	if err := f.skipWhiteSpace(); err != nil {
+		return err
+	}
+	if ch, ok, err := f.peek(); err != nil {
+		return err
+	} else if ok && ch == ']' {
+		_, _ = f.r.ReadByte()
+		f.w.WriteByte(']')
+		return nil
+	}
+
+	f.depth++
+	for {
+		f.newline()
+		if err := f.value(); err != nil {
+			return err
+		}
+		if err := f.skipWhiteSpace(); err != nil {
+			return err
+		}
+		ch, ok, err := f.next()
+		if err != nil {
+			return err
+		}
+		if ok && ch == ',' {
+			f.w.WriteByte(',')
+			if err = f.skipWhiteSpace(); err != nil {
+				return err
+			}
+		} else if ok && ch == ']' {
+			f.depth--
+			f.newline()
+			f.w.WriteByte(']')
+			return nil
+		} else {
+			return ErrParseMissCommaOrSquareBracket
+		}
+	}
+}
+
+func (f *reformatter) object() error {
+	_, _, _ = f.next()
+	f.w.WriteByte('{')
+	if err := f.skipWhiteSpace(); err != nil {
+		return err
+	}
+	if ch, ok, err := f.peek(); err != nil {
+		return err
+	} else if ok && ch == '}' {
+		_, _ = f.r.ReadByte()
+		f.w.WriteByte('}')
+		return nil
+	}
+
+	f.depth++
+	for {
+		if ch, ok, err := f.peek(); err != nil {
+			return err
+		} else if !ok || ch != '"' {
+			return ErrParseMissKey
+		}
+		f.newline()
+		if err := f.string(); err != nil {
+			return err
+		}
+
+		if err := f.skipWhiteSpace(); err != nil {
+			return err
+		}
+		if ch, ok, err := f.next(); err != nil {
+			return err
+		} else if !ok || ch != ':' {
+			return ErrParseMissColon
+		}
+		f.w.WriteByte(':')
+		if f.pretty {
+			f.w.WriteByte(' ')
+		}
+		if err := f.skipWhiteSpace(); err != nil {
+			return err
+		}
+
+		if err := f.value(); err != nil {
+			return err
+		}
+
+		if err := f.skipWhiteSpace(); err != nil {
+			return err
+		}
+		ch, ok, err := f.next()
+		if err != nil {
+			return err
+		}
+		if ok && ch == ',' {
+			f.w.WriteByte(',')
+			if err = f.skipWhiteSpace(); err != nil {
+				return err
+			}
+		} else if ok && ch == '}' {
+			f.depth--
+			f.newline()
+			f.w.WriteByte('}')
+			return nil
+		} else {
+			return ErrParseMissCommaOrCurlyBracket
+		}
+	}
+}
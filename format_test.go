@@ -0,0 +1,59 @@
+package goson
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestCompact(t *testing.T) {
+	f := func(e, s string) {
+		var sb strings.Builder
+		assert.Nil(t, Compact(&sb, strings.NewReader(s)))
+		assert.Equal(t, e, sb.String())
+	}
+	f("", " ")
+	f("null", " null ")
+	f("[]", "[ ]")
+	f("{}", " { \n } ")
+	f("[1.0e+2,-0,\"\\u00A2\\/\\uD834\\uDD1E\"]", "[ 1.0e+2 , -0 , \"\\u00A2\\/\\uD834\\uDD1E\" ]")
+	f("{\"n\":null,\"f\":false,\"t\":true,\"i\":123,\"s\":\"abc\",\"a\":[1,2,3],\"o\":{\"1\":1,\"2\":2,\"3\":3}}",
+		" { \"n\" : null , \"f\" : false , \"t\" : true , \"i\" : 123 , \"s\" : \"abc\", \"a\" : [ 1, 2, 3 ],\"o\" : { \"1\" : 1, \"2\" : 2, \"3\" : 3 } } ")
+}
+
+func TestIndent(t *testing.T) {
+	var p Parser
+	s := "{\"n\":null,\"a\":[1,[],{},[2,{\"b\":\"c\"}]],\"o\":{\"x\":true}}"
+	v, err := p.Parse(s)
+	assert.Nil(t, err)
+	e := Encoder{Indent: "\t"}
+	expect, err := e.Encode(v)
+	assert.Nil(t, err)
+
+	var sb strings.Builder
+	assert.Nil(t, Indent(&sb, strings.NewReader(s), "", "\t"))
+	assert.Equal(t, expect, sb.String())
+
+	sb.Reset()
+	assert.Nil(t, Indent(&sb, strings.NewReader("[1,{\"a\":2}]"), "> ", "  "))
+	assert.Equal(t, "[\n>   1,\n>   {\n>     \"a\": 2\n>   }\n> ]", sb.String())
+}
+
+func TestCompactError(t *testing.T) {
+	for _, s := range []string{
+		"nul", "?", "+0", ".123", "1.", "1e", "INF", "nan", "[1,]", "[\"a\", nul]",
+		"null x", "0123", "0x0", "1e309", "-1e309", "\"", "\"abc",
+		"\"\\v\"", "\"\\'\"", "\"\\0\"", "\"\x01\"", "\"\x1F\"",
+		"\"\\u\"", "\"\\u01\"", "\"\\uG000\"", "\"\\u 123\"",
+		"\"\\uD800\"", "\"\\uD800\\\\\"", "\"\\uD800\\uDBFF\"", "\"\\uD800\\uE000\"",
+		"[1", "[1}", "[1 2", "[[]",
+		"{:1,", "{1:1,", "{[]:1,", "{\"a\":1,",
+		"{\"a\"}", "{\"a\",\"b\"}",
+		"{\"a\":1", "{\"a\":1]", "{\"a\":1 \"b\"", "{\"a\":{}",
+	} {
+		var p Parser
+		_, expect := p.Parse(s)
+		var sb strings.Builder
+		assert.Equal(t, expect, Compact(&sb, strings.NewReader(s)), s)
+	}
+}
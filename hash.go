@@ -0,0 +1,130 @@
+package goson
+
+import (
+	"crypto/sha256"
+	"math"
+	"strconv"
+)
+
+const (
+	fnvOffset64 = 14695981039346656037
+	fnvPrime64  = 1099511628211
+)
+
+type fnv64 uint64
+
+func (h *fnv64) byte(b byte) {
+	*h = (*h ^ fnv64(b)) * fnvPrime64
+}
+
+func (h *fnv64) string(s string) {
+	for i := 0; i < len(s); i++ {
+		h.byte(s[i])
+	}
+}
+
+func (h *fnv64) uint64(n uint64) {
+	for i := 0; i < 8; i++ {
+		h.byte(byte(n >> (8 * i)))
+	}
+}
+
+// Hash returns a 64-bit FNV-1a based hash of v that agrees with isEqual:
+// equal values hash equally. Object members are combined with a
+// commutative sum, so member order does not change the hash, and only the
+// first member of a repeated key is counted. 0 and -0 hash the same, and
+// so do all NaNs.
+func Hash(v *Value) uint64 {
+	return hashValue(v, false)
+}
+
+// hashValue is Hash, except that with unordered set array elements are
+// combined like object members, so that arrays equal as multisets hash
+// equally.
+func hashValue(v *Value, unordered bool) uint64 {
+	h := fnv64(fnvOffset64)
+	h.byte(byte(v.t))
+	switch v.t {
+	case NUMBER:
+		n := v.num()
+		if n == 0 {
+			n = 0
+		} else if math.IsNaN(n) {
+			n = math.NaN()
+		}
+		h.uint64(math.Float64bits(n))
+	case STRING:
+		h.string(v.str())
+	case ARRAY:
+		h.uint64(uint64(len(v.arr())))
+		var sum uint64
+		for _, e := range v.arr() {
+			if unordered {
+				sum += hashValue(e, true)
+			} else {
+				h.uint64(hashValue(e, false))
+			}
+		}
+		h.uint64(sum)
+	case OBJECT:
+		m := memberIndex(v)
+		var sum, n uint64
+		for i, kv := range v.obj() {
+			if firstMember(v, m, kv.k) != i {
+				continue
+			}
+			mh := fnv64(fnvOffset64)
+			mh.string(kv.k)
+			mh.byte(0)
+			mh.uint64(hashValue(kv.v, unordered))
+			sum += uint64(mh)
+			n++
+		}
+		h.uint64(n)
+		h.uint64(sum)
+	}
+	return uint64(h)
+}
+
+// Sum256 returns the SHA-256 digest of the canonical JSON form of v: no
+// white space, object members sorted by key with repeated keys dropped,
+// and numbers in shortest round-trip form with -0 written as 0. Like Hash
+// it agrees with isEqual, but it is also safe against deliberate
+// collisions and stable across releases.
+func Sum256(v *Value) [32]byte {
+	return sha256.Sum256(appendCanonical(nil, v))
+}
+
+func appendCanonical(b []byte, v *Value) []byte {
+	switch v.t {
+	case NUMBER:
+		n := v.num()
+		if n == 0 {
+			n = 0
+		}
+		return strconv.AppendFloat(b, n, 'g', -1, 64)
+	case ARRAY:
+		b = append(b, '[')
+		for i, e := range v.arr() {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = appendCanonical(b, e)
+		}
+		return append(b, ']')
+	case OBJECT:
+		b = append(b, '{')
+		for i, kv := range sortedMembers(v) {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = append(b, stringifyString(kv.k)...)
+			b = append(b, ':')
+			b = appendCanonical(b, kv.v)
+		}
+		return append(b, '}')
+	default:
+		s, _ := v.stringifyValue() // only numbers fail, handled above
+		return append(b, s...)
+	}
+}
@@ -0,0 +1,121 @@
+package goson
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestHash(t *testing.T) {
+	same := func(l, r string) {
+		lv, rv := parseValue(t, l), parseValue(t, r)
+		assert.True(t, isEqual(lv, rv), l+" vs "+r)
+		assert.Equal(t, Hash(lv), Hash(rv), l+" vs "+r)
+		assert.Equal(t, Sum256(lv), Sum256(rv), l+" vs "+r)
+	}
+	same("{\"a\":1,\"b\":[true,null,\"x\"]}", "{\"b\":[true,null,\"x\"],\"a\":1}")
+	same("{\"a\":1,\"a\":2}", "{\"a\":1}")
+	same("0", "-0")
+	same("[1e2]", "[100]")
+
+	distinct := []string{
+		"null", "false", "true", "0", "1", "\"\"", "\"a\"", "[]", "{}", "[null]", "[[]]",
+		"[1,2]", "[2,1]", "{\"a\":1}", "{\"a\":2}", "{\"b\":1}", "{\"a\":1,\"b\":2}", "{\"a\":2,\"b\":1}",
+		"{\"a\":{\"b\":1}}", "{\"ab\":1}", "[\"a\",\"b\"]", "[\"ab\"]",
+	}
+	hashes := map[uint64]string{}
+	sums := map[[32]byte]string{}
+	for _, s := range distinct {
+		v := parseValue(t, s)
+		h := Hash(v)
+		assert.NotContains(t, hashes, h, s+" collides with "+hashes[h])
+		hashes[h] = s
+		sum := Sum256(v)
+		assert.NotContains(t, sums, sum, s+" collides with "+sums[sum])
+		sums[sum] = s
+	}
+}
+
+func TestSet(t *testing.T) {
+	var s Set
+	v := parseValue(t, "{\"a\":[1,2],\"b\":null}")
+	assert.True(t, s.Add(v))
+	assert.False(t, s.Add(parseValue(t, "{\"b\":null,\"a\":[1,2]}")))
+	assert.True(t, s.Add(parseValue(t, "[1,2]")))
+	assert.Equal(t, 2, s.Len())
+
+	_ = v.setObjectValue("c", NewNull())
+	assert.False(t, s.Has(v))
+	assert.True(t, s.Has(parseValue(t, "{\"a\":[1,2],\"b\":null}")))
+	for _, e := range s.Values() {
+		assert.True(t, e.IsFrozen())
+	}
+
+	assert.True(t, s.Remove(parseValue(t, "[1,2]")))
+	assert.False(t, s.Remove(parseValue(t, "[1,2]")))
+	assert.Equal(t, 1, s.Len())
+
+	assert.True(t, s.Add(NewNumber(math.NaN())))
+	assert.False(t, s.Add(NewNumber(math.Float64frombits(math.Float64bits(math.NaN())|1))))
+	assert.True(t, s.Has(NewNumber(math.NaN())))
+	assert.False(t, s.Has(NewArray(NewNumber(math.NaN()))))
+}
+
+func TestMap(t *testing.T) {
+	var m Map[int]
+	m.Set(parseValue(t, "{\"id\":1,\"kind\":\"a\"}"), 1)
+	m.Set(parseValue(t, "{\"kind\":\"a\",\"id\":1}"), 2)
+	m.Set(parseValue(t, "-0"), 3)
+	assert.Equal(t, 2, m.Len())
+
+	n, ok := m.Get(parseValue(t, "{\"id\":1,\"kind\":\"a\"}"))
+	assert.True(t, ok)
+	assert.Equal(t, 2, n)
+	n, ok = m.Get(parseValue(t, "0"))
+	assert.True(t, ok)
+	assert.Equal(t, 3, n)
+	_, ok = m.Get(parseValue(t, "{\"id\":2,\"kind\":\"a\"}"))
+	assert.False(t, ok)
+
+	count := 0
+	m.Range(func(*Value, int) bool { count++; return false })
+	assert.Equal(t, 1, count)
+
+	assert.True(t, m.Delete(parseValue(t, "0")))
+	assert.Equal(t, 1, m.Len())
+}
+
+func TestMapDuplicateKeys(t *testing.T) {
+	v := parseValue(t, "{\"a\":1,\"a\":2}")
+	var m Map[int]
+	m.Set(v, 5)
+	n, ok := m.Get(v)
+	assert.True(t, ok)
+	assert.Equal(t, 5, n)
+
+	var s Set
+	assert.True(t, s.Add(v))
+	assert.False(t, s.Add(v))
+	assert.Equal(t, 1, s.Len())
+	assert.Equal(t, "{\"a\":1,\"a\":2}", stringify(t, s.Values()[0]))
+}
+
+func TestMapArenaKey(t *testing.T) {
+	var a Arena
+	var m Map[int]
+	p := Parser{Arena: &a}
+	k, err := p.Parse("{\"name\":\"alice\"}")
+	assert.Nil(t, err)
+	m.Set(k, 1)
+	a.Reset()
+	_, err = p.Parse("{\"xxxx\":\"xxxxx\"}")
+	assert.Nil(t, err)
+
+	n, ok := m.Get(parseValue(t, "{\"name\":\"alice\"}"))
+	assert.True(t, ok)
+	assert.Equal(t, 1, n)
+	m.Range(func(key *Value, _ int) bool {
+		assert.Equal(t, "{\"name\":\"alice\"}", stringify(t, key))
+		return true
+	})
+}
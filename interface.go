@@ -0,0 +1,381 @@
+package goson
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"unsafe"
+)
+
+// Marshaler is implemented by types that encode themselves as a Value.
+type Marshaler interface {
+	MarshalGoson() (*Value, error)
+}
+
+// EncodeError reports where in a Go value a conversion to a Value failed.
+type EncodeError struct {
+	Path Path
+	Err  error
+}
+
+func (e *EncodeError) Error() string {
+	if len(e.Path) == 0 {
+		return e.Err.Error()
+	}
+	return e.Path.String() + ": " + e.Err.Error()
+}
+
+func (e *EncodeError) Unwrap() error {
+	return e.Err
+}
+
+// Interface converts v into nil, bool, float64, string, []any or
+// map[string]any. When an object repeats a key the first member wins, as
+// with getObjectValue.
+func (v *Value) Interface() any {
+	switch v.t {
+	case FALSE:
+		return false
+	case TRUE:
+		return true
+	case NUMBER:
+		return v.num()
+	case STRING:
+		return v.ownString(v.str())
+	case ARRAY:
+		a := make([]any, len(v.arr()))
+		for i, e := range v.arr() {
+			a[i] = e.Interface()
+		}
+		return a
+	case OBJECT:
+		o := make(map[string]any, len(v.obj()))
+		for _, kv := range v.obj() {
+			if _, ok := o[kv.k]; !ok {
+				o[v.ownString(kv.k)] = kv.v.Interface()
+			}
+		}
+		return o
+	default:
+		return nil
+	}
+}
+
+// FromInterface builds a Value from a Go value made of maps, slices,
+// arrays, structs, numbers, json.Number, strings, booleans, pointers and
+// interfaces. Byte slices become base64 strings, and nil pointers, maps,
+// slices and interfaces become null. Map members are sorted by key and
+// struct fields follow their json tags as in encoding/json. Types that
+// implement Marshaler or encoding.TextMarshaler encode themselves, a
+// Value is copied and a json.RawMessage is parsed. Integers that a
+// float64 cannot hold exactly fail with ErrNumberInexact, and a value
+// that contains itself fails with ErrEncodeCycle.
+func FromInterface(x any) (*Value, error) {
+	var e encoder
+	return e.encode(reflect.ValueOf(x), nil)
+}
+
+func Marshal(x any) ([]byte, error) {
+	var e Encoder
+	return e.Marshal(x)
+}
+
+// Marshal converts x with FromInterface and renders the result with the
+// options of e.
+func (e *Encoder) Marshal(x any) ([]byte, error) {
+	v, err := FromInterface(x)
+	if err != nil {
+		return nil, err
+	}
+	s, err := e.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(s), nil
+}
+
+// startDetectingCycles is the nesting depth of pointers, maps and slices
+// from which the encoder starts looking for cycles. Real data rarely
+// nests that deep, so the common case pays nothing for the check.
+const startDetectingCycles = 1000
+
+type encoder struct {
+	depth int
+	seen  map[visit]struct{}
+}
+
+// visit identifies a pointer, map or slice. Slices sharing an array also
+// need the same length to be the same.
+type visit struct {
+	ptr unsafe.Pointer
+	typ reflect.Type
+	n   int
+}
+
+func visitOf(rv reflect.Value) visit {
+	k := visit{rv.UnsafePointer(), rv.Type(), 0}
+	if rv.Kind() == reflect.Slice {
+		k.n = rv.Len()
+	}
+	return k
+}
+
+// enter notes that rv, a non-nil pointer, map or slice, is being encoded,
+// and fails if rv is already being encoded further up, which means it
+// contains itself. Every successful enter is paired with a leave.
+func (e *encoder) enter(rv reflect.Value, path Path) error {
+	e.depth++
+	if e.depth <= startDetectingCycles {
+		return nil
+	}
+	k := visitOf(rv)
+	if _, ok := e.seen[k]; ok {
+		e.depth--
+		return &EncodeError{path, ErrEncodeCycle}
+	}
+	if e.seen == nil {
+		e.seen = make(map[visit]struct{})
+	}
+	e.seen[k] = struct{}{}
+	return nil
+}
+
+func (e *encoder) leave(rv reflect.Value) {
+	if e.depth > startDetectingCycles {
+		delete(e.seen, visitOf(rv))
+	}
+	e.depth--
+}
+
+var (
+	valueType         = reflect.TypeOf(Value{})
+	numberType        = reflect.TypeOf(json.Number(""))
+	rawMessageType    = reflect.TypeOf(json.RawMessage(nil))
+	marshalerType     = reflect.TypeOf((*Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+func (e *encoder) encode(rv reflect.Value, path Path) (*Value, error) {
+	if !rv.IsValid() {
+		return NewNull(), nil
+	}
+	if (rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface) && rv.IsNil() {
+		return NewNull(), nil
+	}
+	if rv.Kind() != reflect.Pointer && rv.CanAddr() && reflect.PointerTo(rv.Type()).Implements(marshalerType) {
+		rv = rv.Addr()
+	}
+	if rv.Type().Implements(marshalerType) {
+		v, err := rv.Interface().(Marshaler).MarshalGoson()
+		if err != nil {
+			return nil, &EncodeError{path, err}
+		}
+		if v == nil {
+			return NewNull(), nil
+		}
+		return v, nil
+	}
+	if rv.Type().Implements(textMarshalerType) {
+		text, err := rv.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return nil, &EncodeError{path, err}
+		}
+		return NewString(string(text)), nil
+	}
+	switch rv.Type() {
+	case valueType:
+		v := rv.Interface().(Value)
+		return v.copy(), nil
+	case numberType:
+		n, err := strconv.ParseFloat(rv.String(), 64)
+		if err != nil {
+			return nil, &EncodeError{path, fmt.Errorf("invalid number literal %q", rv.String())}
+		}
+		return NewNumber(n), nil
+	case rawMessageType:
+		if rv.IsNil() {
+			return NewNull(), nil
+		}
+		v, err := FromRawMessage(rv.Interface().(json.RawMessage))
+		if err != nil {
+			return nil, &EncodeError{path, err}
+		}
+		return v, nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return NewNull(), nil
+		}
+		if rv.Kind() == reflect.Pointer {
+			if err := e.enter(rv, path); err != nil {
+				return nil, err
+			}
+			defer e.leave(rv)
+		}
+		return e.encode(rv.Elem(), path)
+	case reflect.Bool:
+		return NewBool(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := rv.Int()
+		f := float64(n)
+		if f >= 0x1p63 || int64(f) != n {
+			return nil, &EncodeError{path, ErrNumberInexact}
+		}
+		return NewNumber(f), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n := rv.Uint()
+		f := float64(n)
+		if f >= 0x1p64 || uint64(f) != n {
+			return nil, &EncodeError{path, ErrNumberInexact}
+		}
+		return NewNumber(f), nil
+	case reflect.Float32, reflect.Float64:
+		return NewNumber(rv.Float()), nil
+	case reflect.String:
+		return NewString(rv.String()), nil
+	case reflect.Slice:
+		if rv.IsNil() {
+			return NewNull(), nil
+		}
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return NewString(base64.StdEncoding.EncodeToString(rv.Bytes())), nil
+		}
+		if err := e.enter(rv, path); err != nil {
+			return nil, err
+		}
+		defer e.leave(rv)
+		return e.encodeArray(rv, path)
+	case reflect.Array:
+		return e.encodeArray(rv, path)
+	case reflect.Map:
+		if rv.IsNil() {
+			return NewNull(), nil
+		}
+		if err := e.enter(rv, path); err != nil {
+			return nil, err
+		}
+		defer e.leave(rv)
+		keys := make([]string, 0, rv.Len())
+		values := make(map[string]reflect.Value, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			k, err := keyString(iter.Key())
+			if err != nil {
+				return nil, &EncodeError{path, err}
+			}
+			keys = append(keys, k)
+			values[k] = iter.Value()
+		}
+		sort.Strings(keys)
+		var v Value
+		v.setObject(len(keys))
+		for _, k := range keys {
+			e, err := e.encode(values[k], path.append(k))
+			if err != nil {
+				return nil, err
+			}
+			v.storeObject(append(v.obj(), &KV{k, e}))
+		}
+		return &v, nil
+	case reflect.Struct:
+		fields := cachedFields(rv.Type())
+		var v Value
+		v.setObject(len(fields))
+		for i := range fields {
+			f := &fields[i]
+			fv, ok := fieldValue(rv, f.index)
+			if !ok || f.omitEmpty && isEmptyValue(fv) {
+				continue
+			}
+			e, err := e.encode(fv, path.append(f.name))
+			if err != nil {
+				return nil, err
+			}
+			if f.quoted && isQuotable(fv.Kind()) {
+				s, err := e.stringifyValue()
+				if err != nil {
+					return nil, &EncodeError{path.append(f.name), err}
+				}
+				e = NewString(s)
+			}
+			v.storeObject(append(v.obj(), &KV{f.name, e}))
+		}
+		return &v, nil
+	default:
+		return nil, &EncodeError{path, fmt.Errorf("unsupported type %s", rv.Type())}
+	}
+}
+
+func (e *encoder) encodeArray(rv reflect.Value, path Path) (*Value, error) {
+	var v Value
+	v.setArray(rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		elem, err := e.encode(rv.Index(i), path.append(i))
+		if err != nil {
+			return nil, err
+		}
+		v.storeArray(append(v.arr(), elem))
+	}
+	return &v, nil
+}
+
+func keyString(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+	if k.Type().Implements(textMarshalerType) {
+		text, err := k.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	default:
+		return "", fmt.Errorf("unsupported map key type %s", k.Type())
+	}
+}
+
+func fieldValue(rv reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				return reflect.Value{}, false
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv, true
+}
+
+func isEmptyValue(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return rv.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Interface, reflect.Pointer:
+		return rv.IsZero()
+	default:
+		return false
+	}
+}
+
+func isQuotable(k reflect.Kind) bool {
+	switch k {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.String:
+		return true
+	default:
+		return false
+	}
+}
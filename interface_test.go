@@ -0,0 +1,121 @@
+package goson
+
+import (
+	"encoding"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestInterface(t *testing.T) {
+	v := parseValue(t, "{\"n\":null,\"b\":true,\"f\":false,\"i\":1.5,\"s\":\"x\",\"a\":[1,[]],\"o\":{\"k\":{}},\"n\":1}")
+	assert.Equal(t, map[string]any{
+		"n": nil,
+		"b": true,
+		"f": false,
+		"i": 1.5,
+		"s": "x",
+		"a": []any{1.0, []any{}},
+		"o": map[string]any{"k": map[string]any{}},
+	}, v.Interface())
+	assert.Nil(t, NewNull().Interface())
+}
+
+func TestFromInterface(t *testing.T) {
+	type myString string
+	n := 7
+	var np *int
+	var nm map[string]int
+	var ns []int
+	v, err := FromInterface(map[string]any{
+		"z":   nil,
+		"i8":  int8(-3),
+		"u64": uint64(1 << 40),
+		"f32": float32(0.5),
+		"p":   &n,
+		"np":  np,
+		"nm":  nm,
+		"ns":  ns,
+		"b":   []byte("hello"),
+		"num": json.Number("1e3"),
+		"ms":  myString("s"),
+		"arr": [2]bool{true, false},
+		"sl":  []any{"a", 1, map[myString]uint{"k": 2}},
+		"v":   NewArray(NewString("x")),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "{\"arr\":[true,false],\"b\":\"aGVsbG8=\",\"f32\":0.5,\"i8\":-3,\"ms\":\"s\",\"nm\":null,\"np\":null,"+
+		"\"ns\":null,\"num\":1000,\"p\":7,\"sl\":[\"a\",1,{\"k\":2}],\"u64\":1099511627776,\"v\":[\"x\"],\"z\":null}",
+		stringify(t, v))
+
+	v, err = FromInterface(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, NULL, v.t)
+
+	back, err := FromInterface(parseValue(t, "{\"a\":[1,\"b\",null]}").Interface())
+	assert.Nil(t, err)
+	assert.Equal(t, "{\"a\":[1,\"b\",null]}", stringify(t, back))
+}
+
+func TestFromInterfaceError(t *testing.T) {
+	_, err := FromInterface(make(chan int))
+	assert.EqualError(t, err, "unsupported type chan int")
+	_, err = FromInterface(map[string]any{"a": []any{1, func() {}}})
+	assert.EqualError(t, err, "/a/1: unsupported type func()")
+	_, err = FromInterface(map[bool]string{true: "a"})
+	assert.EqualError(t, err, "unsupported map key type bool")
+	_, err = FromInterface(json.Number("abc"))
+	assert.EqualError(t, err, "invalid number literal \"abc\"")
+	_, err = FromInterface(complex(1, 2))
+	var ee *EncodeError
+	assert.ErrorAs(t, err, &ee)
+}
+
+type node struct {
+	Next *node
+}
+
+func TestFromInterfaceCycle(t *testing.T) {
+	n := &node{}
+	n.Next = n
+	_, err := FromInterface(n)
+	assert.ErrorIs(t, err, ErrEncodeCycle)
+	m := map[string]any{}
+	m["m"] = m
+	_, err = Marshal(m)
+	assert.ErrorIs(t, err, ErrEncodeCycle)
+	s := []any{nil}
+	s[0] = s
+	_, err = FromInterface(s)
+	assert.ErrorIs(t, err, ErrEncodeCycle)
+
+	shared := &node{}
+	v, err := FromInterface([]*node{shared, shared})
+	assert.Nil(t, err)
+	assert.Equal(t, "[{\"Next\":null},{\"Next\":null}]", stringify(t, v))
+}
+
+func TestFromInterfaceNilInterface(t *testing.T) {
+	v, err := FromInterface(struct {
+		T encoding.TextMarshaler
+		M Marshaler
+	}{})
+	assert.Nil(t, err)
+	assert.Equal(t, "{\"T\":null,\"M\":null}", stringify(t, v))
+	b, err := Marshal([]encoding.TextMarshaler{nil})
+	assert.Nil(t, err)
+	assert.Equal(t, "[null]", string(b))
+}
+
+func TestFromInterfaceInexact(t *testing.T) {
+	v, err := FromInterface([]any{int64(1) << 53, -(int64(1) << 53), int64(1) << 60, uint64(1) << 63, int64(math.MinInt64)})
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(v.arr()))
+	for _, x := range []any{int64(1)<<53 + 1, -(int64(1) << 53) - 1, int64(math.MaxInt64), uint64(math.MaxUint64), uint64(1)<<53 + 1} {
+		_, err = FromInterface(x)
+		assert.ErrorIs(t, err, ErrNumberInexact, "%v", x)
+	}
+	_, err = Marshal(struct{ ID int64 }{1<<62 + 1})
+	assert.EqualError(t, err, "/ID: number inexact")
+}
@@ -0,0 +1,39 @@
+package goson
+
+import "encoding/json"
+
+// MarshalJSON lets a *Value sit inside structs handled by encoding/json.
+// A nil *Value is written as null.
+func (v *Value) MarshalJSON() ([]byte, error) {
+	if v == nil {
+		return []byte("null"), nil
+	}
+	var e Encoder
+	s, err := e.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(s), nil
+}
+
+func (v *Value) UnmarshalJSON(data []byte) error {
+	if v.frozen {
+		return ErrValueFrozen
+	}
+	var p Parser
+	nv, err := p.Parse(string(data))
+	if err != nil {
+		return err
+	}
+	*v = *nv
+	return nil
+}
+
+func (v *Value) RawMessage() (json.RawMessage, error) {
+	return v.MarshalJSON()
+}
+
+func FromRawMessage(m json.RawMessage) (*Value, error) {
+	var p Parser
+	return p.Parse(string(m))
+}
@@ -0,0 +1,64 @@
+package goson
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+type document struct {
+	ID   int    `json:"id"`
+	Body *Value `json:"body"`
+	Opt  *Value `json:"opt"`
+}
+
+func TestJSONMarshal(t *testing.T) {
+	d := document{ID: 1, Body: parseValue(t, "{\"a\":[1,\"x\",null],\"b\":{}}")}
+	b, err := json.Marshal(d)
+	assert.Nil(t, err)
+	assert.Equal(t, "{\"id\":1,\"body\":{\"a\":[1,\"x\",null],\"b\":{}},\"opt\":null}", string(b))
+
+	_, err = json.Marshal(NewNumber(math.NaN()))
+	assert.ErrorIs(t, err, ErrNumberNotFinite)
+}
+
+func TestJSONUnmarshal(t *testing.T) {
+	var d document
+	err := json.Unmarshal([]byte("{\"id\":2,\"body\":{\"k\":[true,1.5]},\"opt\":null}"), &d)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, d.ID)
+	assert.Equal(t, "{\"k\":[true,1.5]}", stringify(t, d.Body))
+	assert.Nil(t, d.Opt)
+
+	var v Value
+	assert.Nil(t, json.Unmarshal([]byte("[1, 2]"), &v))
+	assert.Equal(t, "[1,2]", stringify(t, &v))
+}
+
+func TestRawMessage(t *testing.T) {
+	v, err := FromRawMessage(json.RawMessage("{\"a\": [1]}"))
+	assert.Nil(t, err)
+	m, err := v.RawMessage()
+	assert.Nil(t, err)
+	assert.Equal(t, json.RawMessage("{\"a\":[1]}"), m)
+
+	_, err = FromRawMessage(json.RawMessage("{"))
+	assert.Equal(t, ErrParseMissKey, err)
+
+	type wrapper struct {
+		Raw   json.RawMessage `json:"raw"`
+		Value *Value          `json:"value"`
+		Plain Value           `json:"plain"`
+	}
+	w := wrapper{Raw: json.RawMessage("[1, {\"b\":true}]"), Value: NewString("s"), Plain: *NewBool(false)}
+	b, err := Marshal(w)
+	assert.Nil(t, err)
+	assert.Equal(t, "{\"raw\":[1,{\"b\":true}],\"value\":\"s\",\"plain\":false}", string(b))
+
+	var back wrapper
+	assert.Nil(t, Unmarshal(b, &back))
+	assert.Equal(t, json.RawMessage("[1,{\"b\":true}]"), back.Raw)
+	assert.Equal(t, "\"s\"", stringify(t, back.Value))
+	assert.Equal(t, FALSE, back.Plain.t)
+}
@@ -0,0 +1,187 @@
+package goson
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// LookupError reports a path that could not be followed. Path is the
+// deepest prefix that resolved and Segment is the one that did not.
+type LookupError struct {
+	Path    Path
+	Segment any
+	Err     error
+}
+
+func (e *LookupError) Error() string {
+	seg := fmt.Sprint(e.Segment)
+	if s, ok := e.Segment.(string); ok {
+		seg = strconv.Quote(s)
+	}
+	return fmt.Sprintf("%q: segment %s: %v", e.Path.String(), seg, e.Err)
+}
+
+func (e *LookupError) Unwrap() error {
+	return e.Err
+}
+
+type SetOptions struct {
+	// CreateMissing makes SetAtWith create missing intermediate objects
+	// and arrays, turn null nodes on the way into containers, and pad
+	// arrays with nulls up to the index being set.
+	CreateMissing bool
+}
+
+func (v *Value) child(seg any) (*Value, error) {
+	switch seg := seg.(type) {
+	case string:
+		if v.t != OBJECT {
+			return nil, ErrPathTypeMismatch
+		}
+		if i := v.findKey(seg); i >= 0 {
+			return v.obj()[i].v, nil
+		}
+		return nil, ErrKeyNotExist
+	case int:
+		if v.t != ARRAY {
+			return nil, ErrPathTypeMismatch
+		}
+		if seg < 0 || seg >= len(v.arr()) {
+			return nil, ErrIndexOutOfRange
+		}
+		return v.arr()[seg], nil
+	default:
+		return nil, ErrPathInvalidSegment
+	}
+}
+
+// Lookup follows path from v. Each segment is a string naming an object
+// member or an int indexing an array.
+func (v *Value) Lookup(path ...any) (*Value, error) {
+	cur := v
+	for i, seg := range path {
+		next, err := cur.child(seg)
+		if err != nil {
+			return nil, &LookupError{Path(path[:i:i]), seg, err}
+		}
+		cur = next
+	}
+	return cur, nil
+}
+
+func (v *Value) SetAt(value *Value, path ...any) error {
+	return v.SetAtWith(SetOptions{}, value, path...)
+}
+
+// SetAtWith stores value at path, replacing whatever is there. It is all
+// or nothing: the whole path is checked before anything changes, and with
+// CreateMissing the missing containers are built on their own and only
+// attached once nothing can fail.
+func (v *Value) SetAtWith(opts SetOptions, value *Value, path ...any) error {
+	if len(path) == 0 {
+		return ErrPathEmpty
+	}
+	cur := v
+	i := 0
+	for ; i < len(path)-1; i++ {
+		if opts.CreateMissing && cur.t == NULL {
+			break
+		}
+		child, err := cur.child(path[i])
+		if err != nil {
+			if !opts.CreateMissing || err != ErrKeyNotExist && err != ErrIndexOutOfRange {
+				return &LookupError{Path(path[:i:i]), path[i], err}
+			}
+			break
+		}
+		cur = child
+	}
+
+	// path[i] is set in cur and everything below it is new.
+	for j := i; j < len(path); j++ {
+		if err := checkSegment(path[j]); err != nil {
+			return &LookupError{Path(path[:j:j]), path[j], err}
+		}
+	}
+	next := value
+	for j := len(path) - 1; j > i; j-- {
+		next = branch(path[j], next)
+	}
+	if opts.CreateMissing && cur.t == NULL {
+		var err error
+		if _, ok := path[i].(int); ok {
+			err = cur.setArray(0)
+		} else {
+			err = cur.setObject(0)
+		}
+		if err != nil {
+			return &LookupError{Path(path[:i:i]), path[i], err}
+		}
+	}
+	if err := cur.place(path[i], next, opts.CreateMissing); err != nil {
+		return &LookupError{Path(path[:i:i]), path[i], err}
+	}
+	return nil
+}
+
+func checkSegment(seg any) error {
+	switch seg := seg.(type) {
+	case string:
+		return nil
+	case int:
+		if seg < 0 {
+			return ErrIndexOutOfRange
+		}
+		return nil
+	default:
+		return ErrPathInvalidSegment
+	}
+}
+
+// branch returns a new container holding child under seg, with arrays
+// padded with nulls up to the index.
+func branch(seg any, child *Value) *Value {
+	if key, ok := seg.(string); ok {
+		return NewObject(NewKV(key, child))
+	}
+	elems := make([]*Value, seg.(int)+1)
+	for i := range elems {
+		elems[i] = NewNull()
+	}
+	elems[len(elems)-1] = child
+	var v Value
+	v.storeArray(elems)
+	return &v
+}
+
+func (v *Value) place(seg any, value *Value, grow bool) error {
+	switch seg := seg.(type) {
+	case string:
+		if v.t != OBJECT {
+			return ErrPathTypeMismatch
+		}
+		return v.setObjectValue(seg, value)
+	case int:
+		if v.t != ARRAY {
+			return ErrPathTypeMismatch
+		}
+		if seg < 0 || seg >= len(v.arr()) && !grow {
+			return ErrIndexOutOfRange
+		}
+		for len(v.arr()) < seg {
+			if err := v.insertArrayElement(NewNull(), len(v.arr())); err != nil {
+				return err
+			}
+		}
+		if seg == len(v.arr()) {
+			return v.insertArrayElement(value, seg)
+		}
+		if v.frozen {
+			return ErrValueFrozen
+		}
+		v.arr()[seg] = value
+		return nil
+	default:
+		return ErrPathInvalidSegment
+	}
+}
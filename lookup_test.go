@@ -0,0 +1,88 @@
+package goson
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	v := parseValue(t, "{\"servers\":[{},{},{\"tls\":{\"cert\":\"c.pem\"}}]}")
+
+	e, err := v.Lookup("servers", 2, "tls", "cert")
+	assert.Nil(t, err)
+	assert.Equal(t, "c.pem", e.str())
+
+	e, err = v.Lookup()
+	assert.Nil(t, err)
+	assert.Equal(t, v, e)
+
+	_, err = v.Lookup("servers", 1, "tls", "cert")
+	assert.ErrorIs(t, err, ErrKeyNotExist)
+	assert.EqualError(t, err, "\"/servers/1\": segment \"tls\": key not exist")
+	var le *LookupError
+	assert.ErrorAs(t, err, &le)
+	assert.Equal(t, Path{"servers", 1}, le.Path)
+
+	_, err = v.Lookup("servers", 3)
+	assert.ErrorIs(t, err, ErrIndexOutOfRange)
+	assert.EqualError(t, err, "\"/servers\": segment 3: index out of range")
+	_, err = v.Lookup("servers", "0")
+	assert.ErrorIs(t, err, ErrPathTypeMismatch)
+	_, err = v.Lookup(1.5)
+	assert.ErrorIs(t, err, ErrPathInvalidSegment)
+}
+
+func TestSetAt(t *testing.T) {
+	v := parseValue(t, "{\"servers\":[{},{\"tls\":{}}]}")
+
+	assert.Nil(t, v.SetAt(NewString("c.pem"), "servers", 1, "tls", "cert"))
+	assert.Nil(t, v.SetAt(NewNumber(1), "servers", 0))
+	assert.Equal(t, "{\"servers\":[1,{\"tls\":{\"cert\":\"c.pem\"}}]}", stringify(t, v))
+
+	err := v.SetAt(NewNull(), "servers", 2)
+	assert.ErrorIs(t, err, ErrIndexOutOfRange)
+	err = v.SetAt(NewNull(), "servers", 1, "x", "y")
+	assert.ErrorIs(t, err, ErrKeyNotExist)
+	assert.EqualError(t, err, "\"/servers/1\": segment \"x\": key not exist")
+	err = v.SetAt(NewNull(), "servers", 0, "x")
+	assert.ErrorIs(t, err, ErrPathTypeMismatch)
+	assert.ErrorIs(t, v.SetAt(NewNull()), ErrPathEmpty)
+}
+
+func TestSetAtCreateMissing(t *testing.T) {
+	opts := SetOptions{CreateMissing: true}
+
+	var v Value
+	assert.Nil(t, v.SetAtWith(opts, NewString("c.pem"), "servers", 2, "tls", "cert"))
+	assert.Equal(t, "{\"servers\":[null,null,{\"tls\":{\"cert\":\"c.pem\"}}]}", stringify(t, &v))
+
+	assert.Nil(t, v.SetAtWith(opts, NewBool(true), "servers", 0, "on"))
+	assert.Nil(t, v.SetAtWith(opts, NewNumber(1), "servers", 3))
+	assert.Equal(t, "{\"servers\":[{\"on\":true},null,{\"tls\":{\"cert\":\"c.pem\"}},1]}", stringify(t, &v))
+
+	err := v.SetAtWith(opts, NewNull(), "servers", 3, "x")
+	assert.ErrorIs(t, err, ErrPathTypeMismatch)
+	assert.EqualError(t, err, "\"/servers/3\": segment \"x\": path type mismatch")
+}
+
+func TestSetAtCreateMissingAtomic(t *testing.T) {
+	opts := SetOptions{CreateMissing: true}
+	v := parseValue(t, "{\"a\":null,\"b\":[1]}")
+
+	err := v.SetAtWith(opts, NewNull(), "a", "x", -1)
+	assert.EqualError(t, err, "\"/a/x\": segment -1: index out of range")
+	err = v.SetAtWith(opts, NewNull(), "b", 3, "y", 1.5)
+	assert.ErrorIs(t, err, ErrPathInvalidSegment)
+	err = v.SetAtWith(opts, NewNull(), "c", "d", nil)
+	assert.ErrorIs(t, err, ErrPathInvalidSegment)
+	assert.Equal(t, "{\"a\":null,\"b\":[1]}", stringify(t, v))
+
+	b, _ := v.Lookup("b")
+	b.Freeze()
+	err = v.SetAtWith(opts, NewNull(), "b", 3, "y")
+	assert.ErrorIs(t, err, ErrValueFrozen)
+	assert.Equal(t, "{\"a\":null,\"b\":[1]}", stringify(t, v))
+
+	assert.Nil(t, v.SetAtWith(opts, NewNumber(2), "a", "x", 1))
+	assert.Equal(t, "{\"a\":{\"x\":[null,2]},\"b\":[1]}", stringify(t, v))
+}
@@ -0,0 +1,133 @@
+package goson
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"time"
+)
+
+type point struct {
+	X, Y int
+}
+
+func (p point) MarshalGoson() (*Value, error) {
+	return NewArray(NewNumber(float64(p.X)), NewNumber(float64(p.Y))), nil
+}
+
+func (p *point) UnmarshalGoson(v *Value) error {
+	a, err := As[[2]int](v)
+	p.X, p.Y = a[0], a[1]
+	return err
+}
+
+type failing struct{}
+
+func (failing) MarshalGoson() (*Value, error) {
+	return nil, errors.New("boom")
+}
+
+func (l level) MarshalText() ([]byte, error) {
+	switch l {
+	case 1:
+		return []byte("low"), nil
+	case 2:
+		return []byte("high"), nil
+	default:
+		return nil, errors.New("unknown level")
+	}
+}
+
+type Audit struct {
+	Created time.Time `json:"created"`
+	By      string    `json:"by,omitempty"`
+}
+
+type record struct {
+	Audit
+	*Meta
+	Name    string           `json:"name"`
+	Count   int              `json:"count,string"`
+	On      bool             `json:"on,string"`
+	Note    string           `json:"note,omitempty"`
+	Ptr     *int             `json:"ptr,omitempty"`
+	List    []int            `json:"list,omitempty"`
+	At      point            `json:"at"`
+	Level   level            `json:"level"`
+	ByLevel map[level]string `json:"by_level"`
+	Skip    int              `json:"-"`
+	Dash    int              `json:"-,"`
+	Any     any              `json:"any"`
+	hidden  int
+}
+
+func TestMarshal(t *testing.T) {
+	r := record{
+		Audit:   Audit{Created: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		Name:    "a\"b",
+		Count:   42,
+		On:      true,
+		At:      point{1, 2},
+		Level:   2,
+		ByLevel: map[level]string{1: "l", 2: "h"},
+		Skip:    1,
+		Dash:    2,
+		Any:     map[string]any{"k": []any{1.5, nil}},
+		hidden:  3,
+	}
+	b, err := Marshal(r)
+	assert.Nil(t, err)
+	s := `{"created":"2024-01-02T03:04:05Z","name":"a\"b","count":"42","on":"true","at":[1,2],"level":"high",` +
+		`"by_level":{"high":"h","low":"l"},"-":2,"any":{"k":[1.5,null]}}`
+	assert.Equal(t, s, string(b))
+
+	var back record
+	assert.Nil(t, Unmarshal(b, &back))
+	r.Skip, r.hidden = 0, 0
+	assert.Equal(t, r, back)
+
+	r.Meta = &Meta{Tags: []string{"x"}}
+	r.By = "me"
+	v, err := FromInterface(&r)
+	assert.Nil(t, err)
+	e, err := v.Lookup("Tags", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, "x", e.str())
+	e, err = v.Lookup("by")
+	assert.Nil(t, err)
+	assert.Equal(t, "me", e.str())
+}
+
+func TestMarshalEncoder(t *testing.T) {
+	e := Encoder{Indent: "  ", Width: 40}
+	b, err := e.Marshal(map[string]any{"a": []int{1, 2, 3}, "b": point{3, 4}})
+	assert.Nil(t, err)
+	assert.Equal(t, "{\"a\": [1, 2, 3], \"b\": [3, 4]}", string(b))
+
+	_, err = Marshal([]float64{math.NaN()})
+	assert.Equal(t, ErrNumberNotFinite, err)
+	e = Encoder{NonFinite: NonFiniteNull}
+	b, err = e.Marshal([]float64{math.Inf(1)})
+	assert.Nil(t, err)
+	assert.Equal(t, "[null]", string(b))
+}
+
+func TestMarshalError(t *testing.T) {
+	_, err := Marshal(map[string]any{"f": failing{}})
+	assert.EqualError(t, err, "/f: boom")
+	_, err = Marshal(struct {
+		L level `json:"l"`
+	}{L: 3})
+	assert.EqualError(t, err, "/l: unknown level")
+	_, err = Marshal(struct{ C chan int }{})
+	assert.EqualError(t, err, "/C: unsupported type chan int")
+}
+
+func BenchmarkMarshal(b *testing.B) {
+	r := record{Name: "bench", Count: 1, List: []int{1, 2, 3}, ByLevel: map[level]string{1: "l"}}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = Marshal(&r)
+	}
+}
@@ -0,0 +1,151 @@
+package goson
+
+import (
+	"fmt"
+	"sort"
+)
+
+// KeyIndex returns the position of the first member named key, or -1 when
+// there is none or v is not an object.
+func (v *Value) KeyIndex(key string) int {
+	if v.t != OBJECT {
+		return -1
+	}
+	return v.findKey(key)
+}
+
+// MemberAt returns the key and value of the i-th member of the object v.
+func (v *Value) MemberAt(i int) (string, *Value, error) {
+	if v.t != OBJECT {
+		return "", &Value{}, fmt.Errorf("value type is not object")
+	}
+	if i < 0 || i >= len(v.obj()) {
+		return "", &Value{}, ErrIndexOutOfRange
+	}
+	kv := v.obj()[i]
+	return kv.k, kv.v, nil
+}
+
+// RenameKey renames the first member named old to new, keeping its
+// position. It fails with ErrKeyExist when another member is already
+// named new.
+func (v *Value) RenameKey(old, new string) error {
+	if v.frozen {
+		return ErrValueFrozen
+	}
+	if v.t != OBJECT {
+		return fmt.Errorf("value type is not object")
+	}
+	i := v.findKey(old)
+	if i < 0 {
+		return ErrKeyNotExist
+	}
+	if old == new {
+		return nil
+	}
+	if v.findKey(new) >= 0 {
+		return ErrKeyExist
+	}
+	// Members may be shared with a frozen value through writable, so the
+	// KV is replaced rather than renamed in place.
+	o := v.obj()
+	o[i] = &KV{new, o[i].v}
+	v.reindex()
+	return nil
+}
+
+// MoveKey moves the first member named key so that it ends up at position
+// toIndex, shifting the members in between.
+func (v *Value) MoveKey(key string, toIndex int) error {
+	if v.frozen {
+		return ErrValueFrozen
+	}
+	if v.t != OBJECT {
+		return fmt.Errorf("value type is not object")
+	}
+	i := v.findKey(key)
+	if i < 0 {
+		return ErrKeyNotExist
+	}
+	o := v.obj()
+	if toIndex < 0 || toIndex >= len(o) {
+		return ErrIndexOutOfRange
+	}
+	kv := o[i]
+	if i < toIndex {
+		copy(o[i:], o[i+1:toIndex+1])
+	} else {
+		copy(o[toIndex+1:], o[toIndex:i])
+	}
+	o[toIndex] = kv
+	v.reindex()
+	return nil
+}
+
+// reindex refreshes the key index after members have changed position.
+func (v *Value) reindex() {
+	if v.index() != nil {
+		v.buildIndex()
+	}
+}
+
+// SortKeys stably reorders the members of the object v by key using less,
+// or byte-wise order when less is nil. When recursive is set, objects
+// nested anywhere below v, including inside arrays, are sorted too, and v
+// itself may also be an array. Nothing is changed when any object to be
+// sorted is frozen.
+func SortKeys(v *Value, recursive bool, less func(a, b string) bool) error {
+	if v.t != OBJECT && (!recursive || v.t != ARRAY) {
+		return fmt.Errorf("value type is not object")
+	}
+	if less == nil {
+		less = func(a, b string) bool { return a < b }
+	}
+	if frozenObject(v, recursive) {
+		return ErrValueFrozen
+	}
+	sortKeys(v, recursive, less)
+	return nil
+}
+
+func frozenObject(v *Value, recursive bool) bool {
+	if v.t == OBJECT && v.frozen {
+		return true
+	}
+	if !recursive {
+		return false
+	}
+	switch v.t {
+	case ARRAY:
+		for _, e := range v.arr() {
+			if frozenObject(e, true) {
+				return true
+			}
+		}
+	case OBJECT:
+		for _, kv := range v.obj() {
+			if frozenObject(kv.v, true) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func sortKeys(v *Value, recursive bool, less func(a, b string) bool) {
+	switch v.t {
+	case OBJECT:
+		o := v.obj()
+		sort.SliceStable(o, func(i, j int) bool { return less(o[i].k, o[j].k) })
+		v.reindex()
+		if recursive {
+			for _, kv := range v.obj() {
+				sortKeys(kv.v, true, less)
+			}
+		}
+	case ARRAY:
+		for _, e := range v.arr() {
+			sortKeys(e, true, less)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package goson
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestMemberAt(t *testing.T) {
+	v := parseValue(t, "{\"a\":1,\"b\":2}")
+	k, e, err := v.MemberAt(1)
+	assert.Nil(t, err)
+	assert.Equal(t, "b", k)
+	assert.Equal(t, "2", stringify(t, e))
+	_, _, err = v.MemberAt(2)
+	assert.Equal(t, ErrIndexOutOfRange, err)
+	_, _, err = parseValue(t, "[]").MemberAt(0)
+	assert.NotNil(t, err)
+
+	assert.Equal(t, 1, v.KeyIndex("b"))
+	assert.Equal(t, -1, v.KeyIndex("c"))
+	assert.Equal(t, -1, parseValue(t, "[]").KeyIndex("a"))
+}
+
+func TestRenameKey(t *testing.T) {
+	v := parseValue(t, "{\"a\":1,\"b\":2,\"c\":3}")
+	assert.Nil(t, v.RenameKey("b", "x"))
+	assert.Equal(t, "{\"a\":1,\"x\":2,\"c\":3}", stringify(t, v))
+	assert.Nil(t, v.RenameKey("a", "a"))
+	assert.Equal(t, ErrKeyExist, v.RenameKey("a", "c"))
+	assert.Equal(t, ErrKeyNotExist, v.RenameKey("b", "y"))
+
+	frozen := parseValue(t, "{\"a\":1}").Freeze()
+	assert.Equal(t, ErrValueFrozen, frozen.RenameKey("a", "b"))
+	w := frozen.writable()
+	w.frozen = false
+	assert.Nil(t, w.RenameKey("a", "b"))
+	assert.Equal(t, "{\"a\":1}", stringify(t, frozen))
+	assert.Equal(t, "{\"b\":1}", stringify(t, w))
+}
+
+func TestMoveKey(t *testing.T) {
+	v := parseValue(t, "{\"a\":1,\"b\":2,\"c\":3,\"d\":4}")
+	assert.Nil(t, v.MoveKey("a", 2))
+	assert.Equal(t, "{\"b\":2,\"c\":3,\"a\":1,\"d\":4}", stringify(t, v))
+	assert.Nil(t, v.MoveKey("d", 0))
+	assert.Equal(t, "{\"d\":4,\"b\":2,\"c\":3,\"a\":1}", stringify(t, v))
+	assert.Equal(t, ErrIndexOutOfRange, v.MoveKey("d", 4))
+	assert.Equal(t, ErrKeyNotExist, v.MoveKey("e", 0))
+}
+
+func TestMembersIndex(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("{")
+	for i := 0; i < 2*objectIndexThreshold; i++ {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("\"k" + strconv.Itoa(i) + "\":" + strconv.Itoa(i))
+	}
+	b.WriteString("}")
+	v := parseValue(t, b.String())
+	assert.Equal(t, 3, v.KeyIndex("k3"))
+
+	assert.Nil(t, v.MoveKey("k3", 10))
+	assert.Nil(t, v.RenameKey("k5", "x"))
+	assert.Nil(t, SortKeys(v, false, func(a, b string) bool { return a > b }))
+	for i, kv := range v.obj() {
+		assert.Equal(t, i, v.KeyIndex(kv.k))
+	}
+	assert.Equal(t, -1, v.KeyIndex("k5"))
+	assert.Equal(t, 0, v.KeyIndex("x"))
+}
+
+func TestSortKeys(t *testing.T) {
+	v := parseValue(t, "{\"b\":{\"z\":1,\"y\":2},\"a\":[{\"d\":1,\"c\":2}],\"a\":0}")
+	assert.Nil(t, SortKeys(v, false, nil))
+	assert.Equal(t, "{\"a\":[{\"d\":1,\"c\":2}],\"a\":0,\"b\":{\"z\":1,\"y\":2}}", stringify(t, v))
+	assert.Nil(t, SortKeys(v, true, nil))
+	assert.Equal(t, "{\"a\":[{\"c\":2,\"d\":1}],\"a\":0,\"b\":{\"y\":2,\"z\":1}}", stringify(t, v))
+
+	a := parseValue(t, "[{\"b\":1,\"a\":2}]")
+	assert.NotNil(t, SortKeys(a, false, nil))
+	assert.Nil(t, SortKeys(a, true, nil))
+	assert.Equal(t, "[{\"a\":2,\"b\":1}]", stringify(t, a))
+
+	f := parseValue(t, "{\"b\":1,\"a\":{\"d\":1,\"c\":2}}")
+	a, _ = f.getObjectValue("a")
+	a.Freeze()
+	assert.Equal(t, ErrValueFrozen, SortKeys(f, true, nil))
+	assert.Equal(t, "{\"b\":1,\"a\":{\"d\":1,\"c\":2}}", stringify(t, f))
+	assert.Nil(t, SortKeys(f, false, nil))
+	assert.Equal(t, "{\"a\":{\"d\":1,\"c\":2},\"b\":1}", stringify(t, f))
+}
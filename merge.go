@@ -0,0 +1,339 @@
+package goson
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type ArrayStrategy int
+
+const (
+	ArrayReplace ArrayStrategy = iota
+	ArrayAppend
+	ArrayUnion
+	ArrayMergeByKey
+)
+
+type ConflictStrategy int
+
+const (
+	ConflictError ConflictStrategy = iota
+	ConflictSrcWins
+	ConflictDstWins
+)
+
+type MergeStrategy struct {
+	Arrays ArrayStrategy
+	// KeyField names the member that identifies the object elements of
+	// arrays merged with ArrayMergeByKey.
+	KeyField  string
+	Conflicts ConflictStrategy
+}
+
+type MergeOptions struct {
+	MergeStrategy
+	// Paths overrides the strategy for the nodes at the given JSON
+	// Pointers. A "*" segment matches any member or index. When several
+	// pointers match a node, the most specific one wins: reading left to
+	// right, a literal segment beats a "*".
+	Paths map[string]MergeStrategy
+	// NullDeletes makes a null member in src remove that member from dst.
+	NullDeletes bool
+	// Report, if not nil, is filled in with the paths Merge changed.
+	Report *MergeReport
+}
+
+type MergeReport struct {
+	Overridden []Path
+	Added      []Path
+	Deleted    []Path
+}
+
+// MergeError reports a type conflict found by Merge under ConflictError.
+type MergeError struct {
+	Path Path
+	Dst  Type
+	Src  Type
+}
+
+func (e *MergeError) Error() string {
+	msg := fmt.Sprintf("cannot merge %s into %s", e.Src, e.Dst)
+	if len(e.Path) == 0 {
+		return msg
+	}
+	return e.Path.String() + ": " + msg
+}
+
+// Merge deep-merges src into dst. Objects are merged member by member,
+// arrays according to the array strategy, and any other src value
+// replaces the one in dst. Values taken from src are copied.
+//
+// Merge is all or nothing: on error dst and the report are left as they
+// were. To get there, every container that changes is replaced by a new
+// node rather than modified, so pointers taken into those parts of dst
+// before the call keep seeing the old content.
+func Merge(dst, src *Value, opts MergeOptions) error {
+	m := merger{opts: opts}
+	for pointer, strategy := range opts.Paths {
+		m.patterns = append(m.patterns, mergePattern{splitPointer(pointer), strategy})
+	}
+	sort.Slice(m.patterns, func(i, j int) bool {
+		return moreSpecific(m.patterns[i].segments, m.patterns[j].segments)
+	})
+	v, err := m.merge(dst, src, nil)
+	if err != nil || v == dst {
+		return err
+	}
+	if dst.frozen {
+		return ErrValueFrozen
+	}
+	*dst = *v
+	if r := opts.Report; r != nil {
+		r.Overridden = append(r.Overridden, m.report.Overridden...)
+		r.Added = append(r.Added, m.report.Added...)
+		r.Deleted = append(r.Deleted, m.report.Deleted...)
+	}
+	return nil
+}
+
+type mergePattern struct {
+	segments []string
+	strategy MergeStrategy
+}
+
+type merger struct {
+	opts     MergeOptions
+	patterns []mergePattern
+	report   MergeReport
+}
+
+func splitPointer(pointer string) []string {
+	if pointer == "" {
+		return nil
+	}
+	segments := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i, seg := range segments {
+		segments[i] = tokenUnescaper.Replace(seg)
+	}
+	return segments
+}
+
+func (m *merger) strategy(path Path) MergeStrategy {
+	for _, p := range m.patterns {
+		if matchPointer(p.segments, path) {
+			return p.strategy
+		}
+	}
+	return m.opts.MergeStrategy
+}
+
+// moreSpecific orders split pointers so that strategy finds the most
+// specific match first. Pointers of different lengths never match the
+// same path, so they are only ordered to keep the result stable.
+func moreSpecific(a, b []string) bool {
+	if len(a) != len(b) {
+		return len(a) > len(b)
+	}
+	for i := range a {
+		if (a[i] == "*") != (b[i] == "*") {
+			return b[i] == "*"
+		}
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
+
+// matchPointer reports whether path is the one named by the split pointer
+// segments, where a "*" segment matches any key or index.
+func matchPointer(segments []string, path Path) bool {
+	if len(segments) != len(path) {
+		return false
+	}
+	for i, seg := range segments {
+		s, ok := path[i].(string)
+		if !ok {
+			s = strconv.Itoa(path[i].(int))
+		}
+		if seg != "*" && seg != s {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *merger) overridden(path Path) {
+	if m.opts.Report != nil {
+		m.report.Overridden = append(m.report.Overridden, path)
+	}
+}
+
+func (m *merger) added(path Path) {
+	if m.opts.Report != nil {
+		m.report.Added = append(m.report.Added, path)
+	}
+}
+
+func (m *merger) deleted(path Path) {
+	if m.opts.Report != nil {
+		m.report.Deleted = append(m.report.Deleted, path)
+	}
+}
+
+func kind(t Type) Type {
+	if t == FALSE {
+		return TRUE
+	}
+	return t
+}
+
+// merge returns the value that should take the place of dst. It never
+// modifies dst: when something under dst changes, the containers on the
+// way are replaced by shallow copies.
+func (m *merger) merge(dst, src *Value, path Path) (*Value, error) {
+	s := m.strategy(path)
+	if dst.t != NULL && src.t != NULL && kind(dst.t) != kind(src.t) {
+		switch s.Conflicts {
+		case ConflictDstWins:
+			return dst, nil
+		case ConflictError:
+			return nil, &MergeError{path, dst.t, src.t}
+		}
+	}
+
+	switch {
+	case dst.t == OBJECT && src.t == OBJECT:
+		return m.mergeObject(dst, src, path)
+	case dst.t == ARRAY && src.t == ARRAY && s.Arrays != ArrayReplace:
+		return m.mergeArray(dst, src, path, s)
+	}
+	if isEqual(dst, src) {
+		return dst, nil
+	}
+	m.overridden(path)
+	return src.copy(), nil
+}
+
+// owned returns a copy of dst that may be changed, unless out already is
+// one.
+func owned(out, dst *Value) (*Value, error) {
+	if out != dst {
+		return out, nil
+	}
+	if dst.frozen {
+		return nil, ErrValueFrozen
+	}
+	return dst.shallowCopy(), nil
+}
+
+func (m *merger) mergeObject(dst, src *Value, path Path) (*Value, error) {
+	out := dst
+	seen := make(map[string]bool, len(src.obj()))
+	for _, kv := range src.obj() {
+		if seen[kv.k] {
+			continue
+		}
+		seen[kv.k] = true
+		p := path.append(kv.k)
+		i := out.findKey(kv.k)
+
+		var err error
+		if kv.v.t == NULL && m.opts.NullDeletes {
+			if i >= 0 {
+				if out, err = owned(out, dst); err != nil {
+					return nil, err
+				}
+				_ = out.removeObjectValue(kv.k)
+				m.deleted(p)
+			}
+			continue
+		}
+		if i < 0 {
+			if out, err = owned(out, dst); err != nil {
+				return nil, err
+			}
+			_ = out.setObjectValue(kv.k, kv.v.copy())
+			m.added(p)
+			continue
+		}
+		old := out.obj()[i].v
+		v, err := m.merge(old, kv.v, p)
+		if err != nil {
+			return nil, err
+		}
+		if v != old {
+			if out, err = owned(out, dst); err != nil {
+				return nil, err
+			}
+			_ = out.setObjectValue(kv.k, v)
+		}
+	}
+	return out, nil
+}
+
+func (m *merger) mergeArray(dst, src *Value, path Path, s MergeStrategy) (*Value, error) {
+	out := dst
+	var err error
+	for _, e := range src.arr() {
+		switch s.Arrays {
+		case ArrayUnion:
+			if indexOf(out, e) >= 0 {
+				continue
+			}
+		case ArrayMergeByKey:
+			if i := indexByKey(out, e, s.KeyField); i >= 0 {
+				old := out.arr()[i]
+				v, err := m.merge(old, e, path.append(i))
+				if err != nil {
+					return nil, err
+				}
+				if v != old {
+					if out, err = owned(out, dst); err != nil {
+						return nil, err
+					}
+					out.arr()[i] = v
+				}
+				continue
+			}
+		}
+		if out, err = owned(out, dst); err != nil {
+			return nil, err
+		}
+		_ = out.insertArrayElement(e.copy(), len(out.arr()))
+		m.added(path.append(len(out.arr()) - 1))
+	}
+	return out, nil
+}
+
+func indexOf(a, e *Value) int {
+	for i, x := range a.arr() {
+		if isEqual(x, e) {
+			return i
+		}
+	}
+	return -1
+}
+
+func indexByKey(a, e *Value, key string) int {
+	if e.t != OBJECT {
+		return -1
+	}
+	k, err := e.getObjectValue(key)
+	if err != nil {
+		return -1
+	}
+	for i, x := range a.arr() {
+		if x.t != OBJECT {
+			continue
+		}
+		if xk, err := x.getObjectValue(key); err == nil && isEqual(xk, k) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,110 @@
+package goson
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func merged(t *testing.T, dst, src string, opts MergeOptions) string {
+	d := parseValue(t, dst)
+	assert.Nil(t, Merge(d, parseValue(t, src), opts))
+	return stringify(t, d)
+}
+
+func TestMerge(t *testing.T) {
+	opts := MergeOptions{}
+	assert.Equal(t, "{\"a\":1,\"b\":{\"c\":2,\"d\":4},\"e\":5}",
+		merged(t, "{\"a\":1,\"b\":{\"c\":3}}", "{\"b\":{\"c\":2,\"d\":4},\"e\":5}", opts))
+	assert.Equal(t, "{\"a\":[3]}", merged(t, "{\"a\":[1,2]}", "{\"a\":[3]}", opts))
+	assert.Equal(t, "{\"a\":null,\"b\":true}", merged(t, "{\"a\":1,\"b\":false}", "{\"a\":null,\"b\":true}", opts))
+	assert.Equal(t, "{\"x\":1}", merged(t, "[1]", "{\"x\":1}", MergeOptions{MergeStrategy: MergeStrategy{Conflicts: ConflictSrcWins}}))
+	assert.Equal(t, "2", merged(t, "null", "2", opts))
+}
+
+func TestMergeArrays(t *testing.T) {
+	f := func(a ArrayStrategy, e string) {
+		opts := MergeOptions{MergeStrategy: MergeStrategy{Arrays: a}}
+		assert.Equal(t, e, merged(t, "[1,2,{\"k\":1}]", "[2,3,{\"k\":1}]", opts))
+	}
+	f(ArrayReplace, "[2,3,{\"k\":1}]")
+	f(ArrayAppend, "[1,2,{\"k\":1},2,3,{\"k\":1}]")
+	f(ArrayUnion, "[1,2,{\"k\":1},3]")
+
+	opts := MergeOptions{MergeStrategy: MergeStrategy{Arrays: ArrayMergeByKey, KeyField: "id"}}
+	assert.Equal(t, "[{\"id\":1,\"v\":\"a\",\"w\":true},{\"id\":2,\"v\":\"B\"},3,{\"id\":3}]",
+		merged(t, "[{\"id\":1,\"v\":\"a\"},{\"id\":2,\"v\":\"b\"}]", "[{\"id\":2,\"v\":\"B\"},{\"id\":1,\"w\":true},3,{\"id\":3}]", opts))
+}
+
+func TestMergePaths(t *testing.T) {
+	opts := MergeOptions{
+		Paths: map[string]MergeStrategy{
+			"/plugins":         {Arrays: ArrayUnion},
+			"/servers":         {Arrays: ArrayMergeByKey, KeyField: "name"},
+			"/servers/*/ports": {Arrays: ArrayAppend},
+			"/a~1b":            {Conflicts: ConflictDstWins},
+		},
+	}
+	dst := "{\"plugins\":[\"x\"],\"servers\":[{\"name\":\"web\",\"ports\":[80]}],\"tags\":[\"old\"],\"a/b\":1}"
+	src := "{\"plugins\":[\"x\",\"y\"],\"servers\":[{\"name\":\"web\",\"ports\":[443]}],\"tags\":[\"new\"],\"a/b\":\"s\"}"
+	assert.Equal(t, "{\"plugins\":[\"x\",\"y\"],\"servers\":[{\"name\":\"web\",\"ports\":[80,443]}],\"tags\":[\"new\"],\"a/b\":1}",
+		merged(t, dst, src, opts))
+}
+
+func TestMergeNullDeletes(t *testing.T) {
+	var r MergeReport
+	opts := MergeOptions{NullDeletes: true, Report: &r}
+	assert.Equal(t, "{\"b\":{\"d\":2},\"e\":3}",
+		merged(t, "{\"a\":1,\"b\":{\"c\":1,\"d\":1}}", "{\"a\":null,\"b\":{\"c\":null,\"d\":2,\"x\":null},\"e\":3}", opts))
+	assert.Equal(t, []Path{{"b", "d"}}, r.Overridden)
+	assert.Equal(t, []Path{{"e"}}, r.Added)
+	assert.Equal(t, []Path{{"a"}, {"b", "c"}}, r.Deleted)
+}
+
+func TestMergeError(t *testing.T) {
+	d := parseValue(t, "{\"a\":{\"b\":[1]}}")
+	err := Merge(d, parseValue(t, "{\"a\":{\"b\":{}}}"), MergeOptions{})
+	assert.EqualError(t, err, "/a/b: cannot merge object into array")
+	err = Merge(parseValue(t, "1"), parseValue(t, "\"1\""), MergeOptions{})
+	assert.EqualError(t, err, "cannot merge string into number")
+
+	d.Freeze()
+	assert.Equal(t, ErrValueFrozen, Merge(d, parseValue(t, "{\"x\":1}"), MergeOptions{}))
+	assert.Equal(t, ErrValueFrozen, Merge(d, parseValue(t, "[]"), MergeOptions{MergeStrategy: MergeStrategy{Conflicts: ConflictSrcWins}}))
+}
+
+func TestMergePathsOverlap(t *testing.T) {
+	opts := MergeOptions{
+		Paths: map[string]MergeStrategy{
+			"/a/*":   {Arrays: ArrayReplace},
+			"/a/b":   {Arrays: ArrayAppend},
+			"/*/b":   {Arrays: ArrayReplace},
+			"/*/*":   {Arrays: ArrayReplace},
+			"/a/*/c": {Arrays: ArrayUnion},
+			"/*/b/c": {Arrays: ArrayAppend},
+		},
+	}
+	for i := 0; i < 50; i++ {
+		assert.Equal(t, "{\"a\":{\"b\":[1,2],\"x\":[2]}}",
+			merged(t, "{\"a\":{\"b\":[1],\"x\":[1]}}", "{\"a\":{\"b\":[2],\"x\":[2]}}", opts))
+		assert.Equal(t, "{\"a\":{\"b\":{\"c\":[1,2,3]}}}",
+			merged(t, "{\"a\":{\"b\":{\"c\":[1,2]}}}", "{\"a\":{\"b\":{\"c\":[2,3]}}}", opts))
+	}
+}
+
+func TestMergeAtomic(t *testing.T) {
+	var r MergeReport
+	d := parseValue(t, "{\"a\":1,\"b\":1,\"c\":{\"x\":[1]}}")
+	c, _ := d.getObjectValue("c")
+	err := Merge(d, parseValue(t, "{\"a\":2,\"c\":{\"x\":[2],\"y\":1},\"b\":{\"x\":1}}"),
+		MergeOptions{MergeStrategy: MergeStrategy{Arrays: ArrayAppend}, Report: &r})
+	assert.EqualError(t, err, "/b: cannot merge object into number")
+	assert.Equal(t, "{\"a\":1,\"b\":1,\"c\":{\"x\":[1]}}", stringify(t, d))
+	assert.Equal(t, MergeReport{}, r)
+
+	assert.Nil(t, Merge(d, parseValue(t, "{\"a\":2,\"c\":{\"x\":[2]}}"),
+		MergeOptions{MergeStrategy: MergeStrategy{Arrays: ArrayAppend}, Report: &r}))
+	assert.Equal(t, "{\"a\":2,\"b\":1,\"c\":{\"x\":[1,2]}}", stringify(t, d))
+	assert.Equal(t, "{\"x\":[1]}", stringify(t, c))
+	assert.Equal(t, []Path{{"a"}}, r.Overridden)
+	assert.Equal(t, []Path{{"c", "x", 1}}, r.Added)
+}
@@ -7,10 +7,63 @@ import (
 	"unicode/utf8"
 )
 
+// Parser parses JSON text into a Value tree. When Arena is set, the nodes
+// and strings of the tree are allocated from it; Value.Clone copies such a
+// tree out of the arena.
 type Parser struct {
-	json  string
-	stack []byte
-	top   int
+	Arena *Arena
+
+	json    string
+	stack   []byte
+	top     int
+	elems   []*Value
+	members []*KV
+}
+
+func (p *Parser) newValue() *Value {
+	if p.Arena != nil {
+		v := p.Arena.value()
+		v.arena = true
+		return v
+	}
+	return &Value{}
+}
+
+func (p *Parser) newKV() *KV {
+	if p.Arena != nil {
+		return p.Arena.kv()
+	}
+	return &KV{}
+}
+
+func (p *Parser) newString(b []byte) string {
+	if p.Arena != nil {
+		return p.Arena.string(b)
+	}
+	return string(b)
+}
+
+// popElems moves the array elements collected on p.elems since start into
+// a slice of the arena. Only arena parsing collects elements and members
+// on the parser's stacks, so that their slices can be sized exactly.
+func (p *Parser) popElems(start int) []*Value {
+	elems := p.elems[start:]
+	a := p.Arena.elemSlice(elems)
+	for i := range elems {
+		elems[i] = nil
+	}
+	p.elems = p.elems[:start]
+	return a
+}
+
+func (p *Parser) popMembers(start int) []*KV {
+	members := p.members[start:]
+	o := p.Arena.memberSlice(members)
+	for i := range members {
+		members[i] = nil
+	}
+	p.members = p.members[:start]
+	return o
 }
 
 func (p *Parser) push(b byte) {
@@ -37,13 +90,13 @@ func (p *Parser) parseWhiteSpace() {
 }
 
 func (p *Parser) parseLiteral(json string, t Type) (*Value, error) {
-	var v Value
 	if len(p.json) < len(json) || p.json[:len(json)] != json {
-		return &v, ErrParseInvalidValue
+		return &Value{}, ErrParseInvalidValue
 	}
 	p.json = p.json[len(json):]
+	v := p.newValue()
 	v.t = t
-	return &v, nil
+	return v, nil
 }
 
 func isDigit1To9(b byte) bool {
@@ -55,7 +108,6 @@ func isDigit(b byte) bool {
 }
 
 func (p *Parser) parseNumber() (*Value, error) {
-	var v Value
 	i := 0
 
 	if i < len(p.json) && p.json[i] == '-' {
@@ -65,7 +117,7 @@ func (p *Parser) parseNumber() (*Value, error) {
 		i++
 	} else {
 		if i >= len(p.json) || !isDigit1To9(p.json[i]) {
-			return &v, ErrParseInvalidValue
+			return &Value{}, ErrParseInvalidValue
 		}
 		for i++; i < len(p.json) && isDigit(p.json[i]); i++ {
 		}
@@ -73,7 +125,7 @@ func (p *Parser) parseNumber() (*Value, error) {
 	if i < len(p.json) && p.json[i] == '.' {
 		i++
 		if i >= len(p.json) || !isDigit(p.json[i]) {
-			return &v, ErrParseInvalidValue
+			return &Value{}, ErrParseInvalidValue
 		}
 		for i++; i < len(p.json) && isDigit(p.json[i]); i++ {
 		}
@@ -84,7 +136,7 @@ func (p *Parser) parseNumber() (*Value, error) {
 			i++
 		}
 		if i >= len(p.json) || !isDigit(p.json[i]) {
-			return &v, ErrParseInvalidValue
+			return &Value{}, ErrParseInvalidValue
 		}
 		for i++; i < len(p.json) && isDigit(p.json[i]); i++ {
 		}
@@ -92,13 +144,14 @@ func (p *Parser) parseNumber() (*Value, error) {
 
 	n, err := strconv.ParseFloat(p.json[:i], 64)
 	if err != nil && errors.Is(err, strconv.ErrRange) {
-		return &v, ErrParseNumberTooBig
+		return &Value{}, ErrParseNumberTooBig
 	}
 
-	v.t = NUMBER
-	v.n = n
+	nv := p.newValue()
+	nv.t = NUMBER
+	nv.storeNumber(n)
 	p.json = p.json[i:]
-	return &v, nil
+	return nv, nil
 }
 
 func (p *Parser) parseHex4() (uint32, error) {
@@ -134,15 +187,15 @@ func (p *Parser) encodeUTF8(u uint32) {
 }
 
 func (p *Parser) parseString() (*Value, error) {
-	var v Value
 	var err error
 	var s string
 	if s, err = p.parseStringRaw(); err != nil {
-		return &v, err
+		return &Value{}, err
 	}
-	v.t = STRING
-	v.s = s
-	return &v, nil
+	nv := p.newValue()
+	nv.t = STRING
+	nv.storeString(s)
+	return nv, nil
 }
 
 func (p *Parser) parseStringRaw() (string, error) {
@@ -168,7 +221,7 @@ func (p *Parser) parseStringRaw() (string, error) {
 		case '"':
 			l := p.top - head
 			s := p.pop(l)
-			return string(s), nil
+			return p.newString(s), nil
 		case '\\':
 			ch = p.json[0]
 			p.json = p.json[1:]
@@ -242,22 +295,23 @@ func (p *Parser) parseStringRaw() (string, error) {
 }
 
 func (p *Parser) parseArray() (*Value, error) {
-	var v Value
-
 	if len(p.json) == 0 || p.json[0] != '[' {
-		return &v, fmt.Errorf(`missing close '['`)
+		return &Value{}, fmt.Errorf(`missing close '['`)
 	}
 	p.json = p.json[1:]
 
 	p.parseWhiteSpace()
 
+	v := p.newValue()
 	if len(p.json) != 0 && p.json[0] == ']' {
 		p.json = p.json[1:]
 		v.t = ARRAY
-		return &v, nil
+		return v, nil
 	}
 
 	var err error
+	var a []*Value
+	start := len(p.elems)
 
 	for {
 		var e *Value
@@ -265,7 +319,11 @@ func (p *Parser) parseArray() (*Value, error) {
 			break
 		}
 
-		v.a = append(v.a, e)
+		if p.Arena != nil {
+			p.elems = append(p.elems, e)
+		} else {
+			a = append(a, e)
+		}
 
 		p.parseWhiteSpace()
 		if len(p.json) != 0 && p.json[0] == ',' {
@@ -274,38 +332,44 @@ func (p *Parser) parseArray() (*Value, error) {
 		} else if len(p.json) != 0 && p.json[0] == ']' {
 			p.json = p.json[1:]
 			v.t = ARRAY
-			return &v, nil
+			if p.Arena != nil {
+				a = p.popElems(start)
+			}
+			v.storeArray(a)
+			return v, nil
 		} else {
 			err = ErrParseMissCommaOrSquareBracket
 			break
 		}
 	}
 
-	v.a = make([]*Value, 0)
+	if p.Arena != nil {
+		p.popElems(start)
+	}
 
-	return &v, err
+	return v, err
 }
 
 func (p *Parser) parseObject() (*Value, error) {
-	var v Value
-
 	if len(p.json) == 0 || p.json[0] != '{' {
-		return &v, fmt.Errorf(`missing close '{'`)
+		return &Value{}, fmt.Errorf(`missing close '{'`)
 	}
 	p.json = p.json[1:]
 
 	p.parseWhiteSpace()
 
+	v := p.newValue()
 	if len(p.json) != 0 && p.json[0] == '}' {
 		p.json = p.json[1:]
 		v.t = OBJECT
-		return &v, nil
+		return v, nil
 	}
 
 	var err error
+	var o []*KV
+	start := len(p.members)
 
 	for {
-		var kv KV
 		var s string
 		var vv *Value
 		if len(p.json) == 0 || p.json[0] != '"' {
@@ -315,7 +379,6 @@ func (p *Parser) parseObject() (*Value, error) {
 		if s, err = p.parseStringRaw(); err != nil {
 			break
 		}
-		kv.k = s
 
 		p.parseWhiteSpace()
 		if len(p.json) == 0 || p.json[0] != ':' {
@@ -328,9 +391,15 @@ func (p *Parser) parseObject() (*Value, error) {
 		if vv, err = p.parseValue(); err != nil {
 			break
 		}
+		kv := p.newKV()
+		kv.k = s
 		kv.v = vv
 
-		v.o = append(v.o, &kv)
+		if p.Arena != nil {
+			p.members = append(p.members, kv)
+		} else {
+			o = append(o, kv)
+		}
 
 		p.parseWhiteSpace()
 		if len(p.json) != 0 && p.json[0] == ',' {
@@ -339,22 +408,27 @@ func (p *Parser) parseObject() (*Value, error) {
 		} else if len(p.json) != 0 && p.json[0] == '}' {
 			p.json = p.json[1:]
 			v.t = OBJECT
-			return &v, nil
+			if p.Arena != nil {
+				o = p.popMembers(start)
+			}
+			v.storeObject(o)
+			return v, nil
 		} else {
 			err = ErrParseMissCommaOrCurlyBracket
 			break
 		}
 	}
 
-	v.o = make([]*KV, 0)
+	if p.Arena != nil {
+		p.popMembers(start)
+	}
 
-	return &v, err
+	return v, err
 }
 
 func (p *Parser) parseValue() (*Value, error) {
-	var v Value
 	if len(p.json) == 0 {
-		return &v, nil
+		return p.newValue(), nil
 	}
 	switch p.json[0] {
 	case 't':
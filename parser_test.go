@@ -2,6 +2,7 @@ package goson
 
 import (
 	"github.com/stretchr/testify/assert"
+	"math"
 	"strconv"
 	"testing"
 )
@@ -33,7 +34,7 @@ func TestParseNumber(t *testing.T) {
 		v, err := p.Parse(s)
 		assert.Nil(t, err)
 		assert.Equal(t, NUMBER, v.getType())
-		assert.Equal(t, n, v.n)
+		assert.Equal(t, n, v.num())
 	}
 
 	f(0.0, "0")
@@ -96,12 +97,12 @@ func TestParseArray(t *testing.T) {
 	v, err := p.Parse("[ ]")
 	assert.Nil(t, err)
 	assert.Equal(t, ARRAY, v.getType())
-	assert.Equal(t, 0, len(v.a))
+	assert.Equal(t, 0, len(v.arr()))
 
 	v, err = p.Parse("[ null , false , true, 123 , \"abc\" ]")
 	assert.Nil(t, err)
 	assert.Equal(t, ARRAY, v.getType())
-	assert.Equal(t, 5, len(v.a))
+	assert.Equal(t, 5, len(v.arr()))
 
 	e, err := v.getArrayElement(0)
 	assert.Nil(t, err)
@@ -132,12 +133,12 @@ func TestParseArray(t *testing.T) {
 	v, err = p.Parse("[ [ ] , [ 0 ] , [ 0, 1 ] , [ 0, 1, 2 ] ]")
 	assert.Nil(t, err)
 	assert.Equal(t, ARRAY, v.getType())
-	assert.Equal(t, 4, len(v.a))
+	assert.Equal(t, 4, len(v.arr()))
 	for i := 0; i < 4; i++ {
 		e, err = v.getArrayElement(i)
 		assert.Nil(t, err)
 		assert.Equal(t, ARRAY, e.getType())
-		assert.Equal(t, i, len(e.a))
+		assert.Equal(t, i, len(e.arr()))
 		for j := 0; j < i; j++ {
 			ee, err := e.getArrayElement(j)
 			assert.Nil(t, err)
@@ -155,7 +156,7 @@ func TestParseObject(t *testing.T) {
 	v, err := p.Parse("{ }")
 	assert.Nil(t, err)
 	assert.Equal(t, OBJECT, v.getType())
-	assert.Equal(t, 0, len(v.o))
+	assert.Equal(t, 0, len(v.obj()))
 
 	var s string
 	s += " { "
@@ -170,7 +171,7 @@ func TestParseObject(t *testing.T) {
 	v, err = p.Parse(s)
 	assert.Nil(t, err)
 	assert.Equal(t, OBJECT, v.getType())
-	assert.Equal(t, 7, len(v.o))
+	assert.Equal(t, 7, len(v.obj()))
 
 	vv, err := v.getObjectValue("n")
 	assert.Nil(t, err)
@@ -201,7 +202,7 @@ func TestParseObject(t *testing.T) {
 	vv, err = v.getObjectValue("a")
 	assert.Nil(t, err)
 	assert.Equal(t, ARRAY, vv.getType())
-	assert.Equal(t, 3, len(vv.a))
+	assert.Equal(t, 3, len(vv.arr()))
 	for i := 0; i < 3; i++ {
 		e, err := vv.getArrayElement(i)
 		assert.Nil(t, err)
@@ -214,7 +215,7 @@ func TestParseObject(t *testing.T) {
 	vv, err = v.getObjectValue("o")
 	assert.Nil(t, err)
 	assert.Equal(t, OBJECT, vv.getType())
-	assert.Equal(t, 3, len(vv.o))
+	assert.Equal(t, 3, len(vv.obj()))
 	for i := 0; i < 3; i++ {
 		vvv, err := vv.getObjectValue(strconv.Itoa(i + 1))
 		assert.Nil(t, err)
@@ -340,7 +341,7 @@ func parseRoundTrip(t *testing.T, s string) {
 	var p Parser
 	v, err := p.Parse(s)
 	assert.Nil(t, err)
-	ss := v.stringifyValue()
+	ss := stringify(t, v)
 	assert.Equal(t, s, ss)
 }
 
@@ -379,6 +380,7 @@ func TestStringifyString(t *testing.T) {
 	parseRoundTrip(t, "\"Hello\\nWorld\"")
 	parseRoundTrip(t, "\"\\\" \\\\ / \\b \\f \\n \\r \\t\"")
 	parseRoundTrip(t, "\"Hello\\u0000World\"")
+	assert.Equal(t, "\"a\\u001Fé€\\\"\uFFFDb\"", stringifyString("a\x1fé€\"\xffb"))
 }
 
 func TestStringifyArray(t *testing.T) {
@@ -417,6 +419,25 @@ func TestAccessNumber(t *testing.T) {
 	assert.Equal(t, 1234.5, n)
 }
 
+func TestAccessFiniteNumber(t *testing.T) {
+	var v Value
+	assert.Nil(t, v.setFiniteNumber(1234.5))
+	assert.Equal(t, NUMBER, v.getType())
+	assert.Equal(t, ErrNumberNotFinite, v.setFiniteNumber(math.NaN()))
+	assert.Equal(t, ErrNumberNotFinite, v.setFiniteNumber(math.Inf(1)))
+	assert.Equal(t, ErrNumberNotFinite, v.setFiniteNumber(math.Inf(-1)))
+	n, err := v.getNumber()
+	assert.Nil(t, err)
+	assert.Equal(t, 1234.5, n)
+
+	nv, err := NewFiniteNumber(2)
+	assert.Nil(t, err)
+	assert.Equal(t, 2.0, nv.num())
+	nv, err = NewFiniteNumber(math.NaN())
+	assert.Nil(t, nv)
+	assert.Equal(t, ErrNumberNotFinite, err)
+}
+
 func TestAccessString(t *testing.T) {
 	var v Value
 	v.setString("")
@@ -435,14 +456,14 @@ func TestAccessArray(t *testing.T) {
 	for i := 0; i <= 5; i += 5 {
 		v.setArray(i)
 		assert.Equal(t, ARRAY, v.getType())
-		assert.Equal(t, 0, len(v.a))
-		assert.Equal(t, i, cap(v.a))
+		assert.Equal(t, 0, len(v.arr()))
+		assert.Equal(t, i, cap(v.arr()))
 		for j := 0; j < 10; j++ {
 			var e Value
 			e.setNumber(float64(j))
 			_ = v.insertArrayElement(&e, j)
 		}
-		assert.Equal(t, 10, len(v.a))
+		assert.Equal(t, 10, len(v.arr()))
 		for j := 0; j < 10; j++ {
 			e, err := v.getArrayElement(j)
 			assert.Nil(t, err)
@@ -452,9 +473,9 @@ func TestAccessArray(t *testing.T) {
 		}
 	}
 
-	err := v.eraseArrayElement(len(v.a)-1, 1)
+	err := v.eraseArrayElement(len(v.arr())-1, 1)
 	assert.Nil(t, err)
-	assert.Equal(t, 9, len(v.a))
+	assert.Equal(t, 9, len(v.arr()))
 	for j := 0; j < 9; j++ {
 		e, err := v.getArrayElement(j)
 		assert.Nil(t, err)
@@ -465,7 +486,7 @@ func TestAccessArray(t *testing.T) {
 
 	err = v.eraseArrayElement(4, 0)
 	assert.Nil(t, err)
-	assert.Equal(t, 9, len(v.a))
+	assert.Equal(t, 9, len(v.arr()))
 	for j := 0; j < 9; j++ {
 		e, err := v.getArrayElement(j)
 		assert.Nil(t, err)
@@ -476,7 +497,7 @@ func TestAccessArray(t *testing.T) {
 
 	err = v.eraseArrayElement(8, 1)
 	assert.Nil(t, err)
-	assert.Equal(t, 8, len(v.a))
+	assert.Equal(t, 8, len(v.arr()))
 	for j := 0; j < 8; j++ {
 		e, err := v.getArrayElement(j)
 		assert.Nil(t, err)
@@ -487,7 +508,7 @@ func TestAccessArray(t *testing.T) {
 
 	err = v.eraseArrayElement(0, 2)
 	assert.Nil(t, err)
-	assert.Equal(t, 6, len(v.a))
+	assert.Equal(t, 6, len(v.arr()))
 	for j := 0; j < 6; j++ {
 		e, err := v.getArrayElement(j)
 		assert.Nil(t, err)
@@ -502,7 +523,7 @@ func TestAccessArray(t *testing.T) {
 		err = v.insertArrayElement(&e, i)
 		assert.Nil(t, err)
 	}
-	assert.Equal(t, 8, len(v.a))
+	assert.Equal(t, 8, len(v.arr()))
 	for j := 0; j < 8; j++ {
 		e, err := v.getArrayElement(j)
 		assert.Nil(t, err)
@@ -513,7 +534,7 @@ func TestAccessArray(t *testing.T) {
 
 	err = v.clearArray()
 	assert.Nil(t, err)
-	assert.Equal(t, 0, len(v.a))
+	assert.Equal(t, 0, len(v.arr()))
 }
 
 func TestAccessObject(t *testing.T) {
@@ -522,8 +543,8 @@ func TestAccessObject(t *testing.T) {
 	for i := 0; i <= 5; i += 5 {
 		v.setObject(i)
 		assert.Equal(t, OBJECT, v.getType())
-		assert.Equal(t, 0, len(v.o))
-		assert.Equal(t, i, cap(v.o))
+		assert.Equal(t, 0, len(v.obj()))
+		assert.Equal(t, i, cap(v.obj()))
 		for j := 0; j < 10; j++ {
 			k := string(byte('a' + j))
 			var value Value
@@ -547,7 +568,7 @@ func TestAccessObject(t *testing.T) {
 	assert.Nil(t, err)
 	_, err = v.getObjectValue("j")
 	assert.Equal(t, ErrKeyNotExist, err)
-	assert.Equal(t, 9, len(v.o))
+	assert.Equal(t, 9, len(v.obj()))
 
 	_, err = v.getObjectValue("a")
 	assert.Nil(t, err)
@@ -555,7 +576,7 @@ func TestAccessObject(t *testing.T) {
 	assert.Nil(t, err)
 	_, err = v.getObjectValue("a")
 	assert.Equal(t, ErrKeyNotExist, err)
-	assert.Equal(t, 8, len(v.o))
+	assert.Equal(t, 8, len(v.obj()))
 
 	for i := 0; i < 8; i++ {
 		k := string(byte('a' + i + 1))
@@ -572,11 +593,11 @@ func TestAccessObject(t *testing.T) {
 	assert.Nil(t, err)
 	vv, err := v.getObjectValue("Hello")
 	assert.Nil(t, err)
-	assert.Equal(t, "World", vv.s)
+	assert.Equal(t, "World", vv.str())
 
 	err = v.clearObject()
 	assert.Nil(t, err)
-	assert.Equal(t, 0, len(v.o))
+	assert.Equal(t, 0, len(v.obj()))
 }
 
 func TestIsEqual(t *testing.T) {
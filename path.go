@@ -0,0 +1,62 @@
+package goson
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Path addresses a node inside a Value tree. Each segment is either a
+// string, naming an object member, or an int, indexing an array.
+type Path []any
+
+func (p Path) append(seg any) Path {
+	q := make(Path, len(p)+1)
+	copy(q, p)
+	q[len(p)] = seg
+	return q
+}
+
+// String renders p as a JSON Pointer, such as "/servers/2/tls".
+func (p Path) String() string {
+	return p.Pointer().String()
+}
+
+// Dotted renders p in the style of JavaScript member access, such as
+// "servers[2].tls". Keys that are not identifiers are written as quoted
+// subscripts.
+func (p Path) Dotted() string {
+	var b strings.Builder
+	for _, seg := range p {
+		switch seg := seg.(type) {
+		case int:
+			b.WriteByte('[')
+			b.WriteString(strconv.Itoa(seg))
+			b.WriteByte(']')
+		case string:
+			if isIdentifier(seg) {
+				if b.Len() > 0 {
+					b.WriteByte('.')
+				}
+				b.WriteString(seg)
+			} else {
+				b.WriteByte('[')
+				b.WriteString(stringifyString(seg))
+				b.WriteByte(']')
+			}
+		}
+	}
+	return b.String()
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if !(ch == '_' || ch == '$' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || i > 0 && isDigit(ch)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,19 @@
+package goson
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPathString(t *testing.T) {
+	assert.Equal(t, "", Path{}.String())
+	assert.Equal(t, "/servers/2/tls", Path{"servers", 2, "tls"}.String())
+	assert.Equal(t, "/a~1b/m~0n/", Path{"a/b", "m~n", ""}.String())
+}
+
+func TestPathDotted(t *testing.T) {
+	assert.Equal(t, "", Path{}.Dotted())
+	assert.Equal(t, "servers[2].tls", Path{"servers", 2, "tls"}.Dotted())
+	assert.Equal(t, "[0][\"a.b\"].$c[\"\"]", Path{0, "a.b", "$c", ""}.Dotted())
+	assert.Equal(t, "a[\"1x\"]", Path{"a", "1x"}.Dotted())
+}
@@ -0,0 +1,137 @@
+package goson
+
+// Freeze makes v and everything under it immutable and returns v. Every
+// mutator of a frozen Value fails with ErrValueFrozen. Subtrees that are
+// frozen already are not visited again, so freezing a Value built with
+// With only touches its new nodes. The first Freeze of a tree walks all of
+// it, and builds the key index of its large objects, so it costs time
+// linear in the size of the tree; freezing it again is O(1).
+func (v *Value) Freeze() *Value {
+	if v.frozen {
+		return v
+	}
+	switch v.t {
+	case ARRAY:
+		for _, e := range v.arr() {
+			e.Freeze()
+		}
+	case OBJECT:
+		for _, kv := range v.obj() {
+			kv.v.Freeze()
+		}
+	}
+	v.buildFrozenIndex()
+	v.frozen = true
+	return v
+}
+
+func (v *Value) IsFrozen() bool {
+	return v.frozen
+}
+
+// buildFrozenIndex builds the key index of a large object up front, since
+// findKey must not write to a frozen Value that may be shared between
+// goroutines.
+func (v *Value) buildFrozenIndex() {
+	if v.t == OBJECT && v.index() == nil && len(v.obj()) >= objectIndexThreshold {
+		v.buildIndex()
+	}
+}
+
+// writable returns v itself if it is mutable, or else a shallow copy of it
+// that can be changed without affecting v. The copy keeps v's frozen flag
+// and shares its children, but has its own list, members and key index.
+func (v *Value) writable() *Value {
+	if !v.frozen {
+		return v
+	}
+	nv := v.shallowCopy()
+	if idx := v.index(); idx != nil {
+		m := make(map[string]int, len(idx))
+		for k, i := range idx {
+			m[k] = i
+		}
+		nv.setIndex(m)
+	}
+	nv.frozen = true
+	return nv
+}
+
+// With returns a frozen copy of v in which the node at path is replaced by
+// value, or added when the last segment names a missing object member or
+// is the length of an array. Only the nodes along path are copied; every
+// other subtree is shared with v, which is why sharing needs v to be
+// frozen: With never changes v or value, and uses a frozen deep copy of
+// either one that is not frozen yet. Freeze a tree once to build versions
+// of it cheaply.
+func (v *Value) With(value *Value, path ...any) (*Value, error) {
+	if !v.frozen {
+		v = v.copy().Freeze()
+	}
+	if !value.frozen {
+		value = value.copy().Freeze()
+	}
+	return with(v, value, path, 0)
+}
+
+func with(v, value *Value, path []any, i int) (*Value, error) {
+	if i == len(path) {
+		return value, nil
+	}
+	last := i == len(path)-1
+	seg := path[i]
+	fail := func(err error) (*Value, error) {
+		return nil, &LookupError{Path(path[:i:i]), seg, err}
+	}
+
+	switch seg := seg.(type) {
+	case string:
+		if v.t != OBJECT {
+			return fail(ErrPathTypeMismatch)
+		}
+		j := v.findKey(seg)
+		if j < 0 && !last {
+			return fail(ErrKeyNotExist)
+		}
+		if j < 0 {
+			nv := v.writable()
+			idx := nv.index()
+			nv.storeObject(append(nv.obj(), &KV{seg, value}))
+			if idx != nil {
+				idx[seg] = len(nv.obj()) - 1
+				nv.setIndex(idx)
+			} else {
+				nv.buildFrozenIndex()
+			}
+			return nv, nil
+		}
+		child, err := with(v.obj()[j].v, value, path, i+1)
+		if err != nil {
+			return nil, err
+		}
+		nv := v.writable()
+		nv.obj()[j].v = child
+		return nv, nil
+	case int:
+		if v.t != ARRAY {
+			return fail(ErrPathTypeMismatch)
+		}
+		if seg == len(v.arr()) && last {
+			nv := v.writable()
+			nv.storeArray(append(nv.arr(), value))
+			return nv, nil
+		}
+		if seg < 0 || seg >= len(v.arr()) {
+			return fail(ErrIndexOutOfRange)
+		}
+		child, err := with(v.arr()[seg], value, path, i+1)
+		if err != nil {
+			return nil, err
+		}
+		nv := v.writable()
+		nv.arr()[seg] = child
+		return nv, nil
+	default:
+		return fail(ErrPathInvalidSegment)
+	}
+}
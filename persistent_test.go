@@ -0,0 +1,161 @@
+package goson
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"testing"
+)
+
+func TestFreeze(t *testing.T) {
+	v := parseValue(t, "{\"a\":[1,{\"b\":2}],\"c\":\"d\"}")
+	assert.Equal(t, v, v.Freeze())
+	assert.True(t, v.IsFrozen())
+
+	a, _ := v.getObjectValue("a")
+	b, _ := a.getArrayElement(1)
+	assert.True(t, b.IsFrozen())
+
+	assert.Equal(t, ErrValueFrozen, v.setNull())
+	assert.Equal(t, ErrValueFrozen, v.setBoolean(true))
+	assert.Equal(t, ErrValueFrozen, v.setNumber(1))
+	assert.Equal(t, ErrValueFrozen, v.setString("x"))
+	assert.Equal(t, ErrValueFrozen, v.setArray(0))
+	assert.Equal(t, ErrValueFrozen, v.setObject(0))
+	assert.Equal(t, ErrValueFrozen, v.setObjectValue("x", NewNull()))
+	assert.Equal(t, ErrValueFrozen, v.removeObjectValue("a"))
+	assert.Equal(t, ErrValueFrozen, v.clearObject())
+	assert.Equal(t, ErrValueFrozen, a.insertArrayElement(NewNull(), 0))
+	assert.Equal(t, ErrValueFrozen, a.eraseArrayElement(0, 1))
+	assert.Equal(t, ErrValueFrozen, a.clearArray())
+	assert.ErrorIs(t, v.SetAt(NewNull(), "a", 0), ErrValueFrozen)
+	assert.ErrorIs(t, v.SetAt(NewNull(), "a", 1, "b"), ErrValueFrozen)
+	assert.Equal(t, ErrValueFrozen, v.UnmarshalJSON([]byte("1")))
+	assert.Equal(t, ErrValueFrozen, v.Scan("1"))
+	assert.Equal(t, "{\"a\":[1,{\"b\":2}],\"c\":\"d\"}", stringify(t, v))
+
+	m := NewArray(b)
+	assert.Nil(t, m.setNull())
+	assert.Equal(t, "{\"b\":2}", stringify(t, b))
+}
+
+func TestWith(t *testing.T) {
+	v := parseValue(t, "{\"a\":[1,{\"b\":2}],\"c\":{\"d\":\"e\"}}").Freeze()
+	w, err := v.With(NewNumber(3), "a", 1, "b")
+	assert.Nil(t, err)
+	assert.Equal(t, "{\"a\":[1,{\"b\":2}],\"c\":{\"d\":\"e\"}}", stringify(t, v))
+	assert.Equal(t, "{\"a\":[1,{\"b\":3}],\"c\":{\"d\":\"e\"}}", stringify(t, w))
+	assert.True(t, v.IsFrozen())
+	assert.True(t, w.IsFrozen())
+
+	vc, _ := v.Lookup("c")
+	wc, _ := w.Lookup("c")
+	assert.Same(t, vc, wc)
+	va0, _ := v.Lookup("a", 0)
+	wa0, _ := w.Lookup("a", 0)
+	assert.Same(t, va0, wa0)
+	va, _ := v.Lookup("a")
+	wa, _ := w.Lookup("a")
+	assert.NotSame(t, va, wa)
+
+	w2, err := w.With(NewBool(true), "a", 2)
+	assert.Nil(t, err)
+	w2, err = w2.With(NewNull(), "f")
+	assert.Nil(t, err)
+	assert.Equal(t, "{\"a\":[1,{\"b\":3},true],\"c\":{\"d\":\"e\"},\"f\":null}", stringify(t, w2))
+	assert.Equal(t, "{\"a\":[1,{\"b\":3}],\"c\":{\"d\":\"e\"}}", stringify(t, w))
+
+	_, err = v.With(NewNull(), "a", 3)
+	assert.ErrorIs(t, err, ErrIndexOutOfRange)
+	_, err = v.With(NewNull(), "x", "y")
+	assert.EqualError(t, err, "\"\": segment \"x\": key not exist")
+	_, err = v.With(NewNull(), "c", 0)
+	assert.ErrorIs(t, err, ErrPathTypeMismatch)
+}
+
+func TestWithDuplicateKeys(t *testing.T) {
+	v := parseValue(t, "{\"a\":1,\"a\":2}")
+	w, err := v.With(NewBool(true), "b")
+	assert.Nil(t, err)
+	assert.Equal(t, "{\"a\":1,\"a\":2,\"b\":true}", stringify(t, w))
+	a, _ := w.Lookup("a")
+	assert.Equal(t, 1.0, a.num())
+}
+
+func TestWithUnfrozen(t *testing.T) {
+	v := parseValue(t, "{\"a\":{\"b\":1}}")
+	value := parseValue(t, "[2]")
+	w, err := v.With(value, "a", "b")
+	assert.Nil(t, err)
+	assert.False(t, v.IsFrozen())
+	assert.False(t, value.IsFrozen())
+	assert.True(t, w.IsFrozen())
+	wb, _ := w.Lookup("a", "b")
+	assert.NotSame(t, value, wb)
+	assert.True(t, wb.IsFrozen())
+
+	assert.Nil(t, v.SetAt(NewNumber(3), "a", "c"))
+	assert.Nil(t, value.insertArrayElement(NewNumber(4), 1))
+	assert.Equal(t, "{\"a\":{\"b\":[2]}}", stringify(t, w))
+}
+
+func TestWithLargeObject(t *testing.T) {
+	var v Value
+	v.setObject(0)
+	for i := 0; i < 20; i++ {
+		_ = v.setObjectValue(strconv.Itoa(i), NewNumber(float64(i)))
+	}
+	v.setIndex(nil)
+	v.Freeze()
+	assert.NotNil(t, v.index())
+
+	w, err := v.With(NewString("x"), "new")
+	assert.Nil(t, err)
+	assert.NotNil(t, w.index())
+	assert.Equal(t, 20, len(v.index()))
+	w2, err := w.With(NewString("y"), "0")
+	assert.Nil(t, err)
+	assert.Equal(t, 21, len(w2.index()))
+	e0, _ := w2.getObjectValue("0")
+	assert.Equal(t, "y", e0.str())
+	e0, _ = w.getObjectValue("0")
+	assert.Equal(t, 0.0, e0.num())
+	assert.NotSame(t, w.obj()[0], w2.obj()[0])
+	e, err := w.getObjectValue("new")
+	assert.Nil(t, err)
+	assert.Equal(t, "x", e.str())
+	_, err = v.getObjectValue("new")
+	assert.Equal(t, ErrKeyNotExist, err)
+}
+
+func TestRewriteFrozen(t *testing.T) {
+	v := parseValue(t, "{\"a\":[1,2],\"b\":{\"c\":3}}").Freeze()
+	w := Rewrite(v, func(path Path, node *Value) (*Value, WalkAction) {
+		if node.t == NUMBER && node.num() == 2 {
+			return NewNumber(20), Continue
+		}
+		return node, Continue
+	})
+	assert.Equal(t, "{\"a\":[1,2],\"b\":{\"c\":3}}", stringify(t, v))
+	assert.Equal(t, "{\"a\":[1,20],\"b\":{\"c\":3}}", stringify(t, w))
+	assert.True(t, w.IsFrozen())
+	wa1, _ := w.Lookup("a", 1)
+	assert.True(t, wa1.IsFrozen())
+	vb, _ := v.Lookup("b")
+	wb, _ := w.Lookup("b")
+	assert.Same(t, vb, wb)
+
+	repl := NewObject()
+	w = Rewrite(v, func(path Path, node *Value) (*Value, WalkAction) {
+		if len(path) == 1 && path[0] == "b" {
+			return repl, SkipChildren
+		}
+		return node, Continue
+	})
+	assert.Equal(t, "{\"a\":[1,2],\"b\":{}}", stringify(t, w))
+	assert.Equal(t, "{\"a\":[1,2],\"b\":{\"c\":3}}", stringify(t, v))
+	assert.False(t, repl.IsFrozen())
+	assert.Nil(t, repl.setObjectValue("x", NewNull()))
+	wb, _ = w.Lookup("b")
+	assert.True(t, wb.IsFrozen())
+	assert.Equal(t, "{}", stringify(t, wb))
+}
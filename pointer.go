@@ -0,0 +1,209 @@
+package goson
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+var (
+	tokenEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	tokenUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+// Pointer is a parsed JSON Pointer as defined by RFC 6901, holding its
+// reference tokens unescaped. The empty Pointer refers to the whole
+// document.
+type Pointer []string
+
+// ParsePointer parses a JSON Pointer such as "/a/b~1c/0", or its URI
+// fragment form such as "#/a%20b".
+func ParsePointer(s string) (Pointer, error) {
+	if strings.HasPrefix(s, "#") {
+		var err error
+		if s, err = url.PathUnescape(s[1:]); err != nil {
+			return nil, ErrPointerInvalid
+		}
+	}
+	if s == "" {
+		return Pointer{}, nil
+	}
+	if s[0] != '/' {
+		return nil, ErrPointerInvalid
+	}
+	p := strings.Split(s[1:], "/")
+	for i, tok := range p {
+		for j := 0; j < len(tok); j++ {
+			if tok[j] == '~' && (j+1 == len(tok) || tok[j+1] != '0' && tok[j+1] != '1') {
+				return nil, ErrPointerInvalid
+			}
+		}
+		p[i] = tokenUnescaper.Replace(tok)
+	}
+	return p, nil
+}
+
+// Pointer converts p to a JSON Pointer.
+func (p Path) Pointer() Pointer {
+	q := make(Pointer, len(p))
+	for i, seg := range p {
+		switch seg := seg.(type) {
+		case int:
+			q[i] = strconv.Itoa(seg)
+		case string:
+			q[i] = seg
+		}
+	}
+	return q
+}
+
+// String renders p as a JSON Pointer, such as "/a/b~1c/0", escaping "~"
+// and "/" in its tokens. The empty Pointer renders as "".
+func (p Pointer) String() string {
+	var b strings.Builder
+	for _, tok := range p {
+		b.WriteByte('/')
+		b.WriteString(tokenEscaper.Replace(tok))
+	}
+	return b.String()
+}
+
+// Fragment renders p in URI fragment form, such as "#/a%20b".
+func (p Pointer) Fragment() string {
+	var b strings.Builder
+	b.WriteByte('#')
+	for _, tok := range p {
+		b.WriteByte('/')
+		b.WriteString(url.PathEscape(tokenEscaper.Replace(tok)))
+	}
+	return b.String()
+}
+
+// arrayIndex parses an array index token, which must be a decimal number
+// without leading zeros.
+func arrayIndex(tok string) (int, bool) {
+	if tok == "" || len(tok) > 1 && tok[0] == '0' {
+		return 0, false
+	}
+	for i := 0; i < len(tok); i++ {
+		if !isDigit(tok[i]) {
+			return 0, false
+		}
+	}
+	i, err := strconv.Atoi(tok)
+	return i, err == nil
+}
+
+// step resolves tok against v, returning the segment it names and the
+// child found there.
+func step(v *Value, tok string) (any, *Value, error) {
+	switch v.t {
+	case OBJECT:
+		child, err := v.getObjectValue(tok)
+		if err != nil {
+			return tok, nil, err
+		}
+		return tok, child, nil
+	case ARRAY:
+		if tok == "-" {
+			return tok, nil, ErrIndexOutOfRange
+		}
+		i, ok := arrayIndex(tok)
+		if !ok {
+			return tok, nil, ErrPathInvalidSegment
+		}
+		if i >= len(v.arr()) {
+			return i, nil, ErrIndexOutOfRange
+		}
+		child, err := v.getArrayElement(i)
+		return i, child, err
+	default:
+		return tok, nil, ErrPathTypeMismatch
+	}
+}
+
+// resolve follows tokens from v, also returning the Path they name.
+func resolve(v *Value, tokens []string) (*Value, Path, error) {
+	var path Path
+	for _, tok := range tokens {
+		seg, child, err := step(v, tok)
+		if err != nil {
+			return nil, path, &LookupError{path, seg, err}
+		}
+		path = path.append(seg)
+		v = child
+	}
+	return v, path, nil
+}
+
+// Get returns the node p refers to in v.
+func (p Pointer) Get(v *Value) (*Value, error) {
+	v, _, err := resolve(v, p)
+	return v, err
+}
+
+// Exists reports whether p refers to a node in v.
+func (p Pointer) Exists(v *Value) bool {
+	_, err := p.Get(v)
+	return err == nil
+}
+
+// Set stores x at p, replacing an existing node or adding a missing object
+// member. The last token "-" appends x to an array.
+func (p Pointer) Set(v, x *Value) error {
+	if len(p) == 0 {
+		return ErrPathEmpty
+	}
+	parent, path, err := resolve(v, p[:len(p)-1])
+	if err != nil {
+		return err
+	}
+	tok := p[len(p)-1]
+	var seg any = tok
+	switch parent.t {
+	case OBJECT:
+		err = parent.setObjectValue(tok, x)
+	case ARRAY:
+		if tok == "-" {
+			err = parent.insertArrayElement(x, len(parent.arr()))
+			break
+		}
+		i, ok := arrayIndex(tok)
+		if !ok {
+			err = ErrPathInvalidSegment
+			break
+		}
+		seg = i
+		err = parent.place(i, x, false)
+	default:
+		err = ErrPathTypeMismatch
+	}
+	if err != nil {
+		return &LookupError{path, seg, err}
+	}
+	return nil
+}
+
+// Delete removes the node p refers to from its parent.
+func (p Pointer) Delete(v *Value) error {
+	if len(p) == 0 {
+		return ErrPathEmpty
+	}
+	parent, path, err := resolve(v, p[:len(p)-1])
+	if err != nil {
+		return err
+	}
+	seg, _, err := step(parent, p[len(p)-1])
+	if err == nil {
+		switch seg := seg.(type) {
+		case string:
+			err = parent.removeObjectValue(seg)
+		case int:
+			err = parent.eraseArrayElement(seg, 1)
+		}
+	}
+	if err != nil {
+		return &LookupError{path, seg, err}
+	}
+	return nil
+}
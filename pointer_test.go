@@ -0,0 +1,113 @@
+package goson
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func mustPointer(t *testing.T, s string) Pointer {
+	p, err := ParsePointer(s)
+	assert.Nil(t, err)
+	return p
+}
+
+func TestParsePointer(t *testing.T) {
+	assert.Equal(t, Pointer{}, mustPointer(t, ""))
+	assert.Equal(t, Pointer{""}, mustPointer(t, "/"))
+	assert.Equal(t, Pointer{"a", "b/c", "0"}, mustPointer(t, "/a/b~1c/0"))
+	assert.Equal(t, Pointer{"m~n", "~1"}, mustPointer(t, "/m~0n/~01"))
+	assert.Equal(t, Pointer{"a b", "c%d"}, mustPointer(t, "#/a%20b/c%25d"))
+	assert.Equal(t, Pointer{}, mustPointer(t, "#"))
+
+	for _, s := range []string{"a", "/a~", "/a~2", "#a", "#/a%2"} {
+		_, err := ParsePointer(s)
+		assert.Equal(t, ErrPointerInvalid, err, s)
+	}
+}
+
+func TestPointerString(t *testing.T) {
+	for _, s := range []string{"", "/", "/a/b~1c/0", "/m~0n/~01", "/a b/c%d"} {
+		assert.Equal(t, s, mustPointer(t, s).String())
+	}
+	assert.Equal(t, "#/a%20b/c%25d/~0~1", Pointer{"a b", "c%d", "~/"}.Fragment())
+	assert.Equal(t, "#", Pointer{}.Fragment())
+	assert.Equal(t, Pointer{"servers", "2", "a/b"}, Path{"servers", 2, "a/b"}.Pointer())
+}
+
+func TestPointerGet(t *testing.T) {
+	// The example document of RFC 6901, section 5.
+	v := parseValue(t, "{\"foo\":[\"bar\",\"baz\"],\"\":0,\"a/b\":1,\"c%d\":2,\"e^f\":3,\"g|h\":4,"+
+		"\"i\\\\j\":5,\"k\\\"l\":6,\" \":7,\"m~n\":8}")
+	f := func(s, want string) {
+		e, err := mustPointer(t, s).Get(v)
+		assert.Nil(t, err, s)
+		if err == nil {
+			assert.Equal(t, want, stringify(t, e), s)
+		}
+	}
+	f("", stringify(t, v))
+	f("/foo", "[\"bar\",\"baz\"]")
+	f("/foo/0", "\"bar\"")
+	f("/", "0")
+	f("/a~1b", "1")
+	f("/c%d", "2")
+	f("/e^f", "3")
+	f("/g|h", "4")
+	f("/i\\j", "5")
+	f("/k\"l", "6")
+	f("/ ", "7")
+	f("/m~0n", "8")
+	f("#/foo/1", "\"baz\"")
+	f("#/c%25d", "2")
+	f("#/%20", "7")
+
+	g := func(s string, err error, msg string) {
+		_, e := mustPointer(t, s).Get(v)
+		assert.True(t, errors.Is(e, err), s)
+		assert.Equal(t, msg, e.Error(), s)
+		assert.False(t, mustPointer(t, s).Exists(v), s)
+	}
+	g("/x", ErrKeyNotExist, "\"\": segment \"x\": key not exist")
+	g("/foo/2", ErrIndexOutOfRange, "\"/foo\": segment 2: index out of range")
+	g("/foo/-", ErrIndexOutOfRange, "\"/foo\": segment \"-\": index out of range")
+	g("/foo/01", ErrPathInvalidSegment, "\"/foo\": segment \"01\": path invalid segment")
+	g("/foo/+1", ErrPathInvalidSegment, "\"/foo\": segment \"+1\": path invalid segment")
+	g("/foo/0/x", ErrPathTypeMismatch, "\"/foo/0\": segment \"x\": path type mismatch")
+	assert.True(t, mustPointer(t, "/m~0n").Exists(v))
+}
+
+func TestPointerSet(t *testing.T) {
+	v := parseValue(t, "{\"a\":[1,2],\"b\":{}}")
+	assert.Nil(t, mustPointer(t, "/a/-").Set(v, NewNumber(3)))
+	assert.Nil(t, mustPointer(t, "/a/0").Set(v, NewString("x")))
+	assert.Nil(t, mustPointer(t, "/b/c~1d").Set(v, NewBool(true)))
+	assert.Nil(t, mustPointer(t, "/b/c~1d").Set(v, NewNull()))
+	assert.Equal(t, "{\"a\":[\"x\",2,3],\"b\":{\"c/d\":null}}", stringify(t, v))
+
+	assert.Equal(t, ErrPathEmpty, Pointer{}.Set(v, NewNull()))
+	assert.True(t, errors.Is(mustPointer(t, "/a/3").Set(v, NewNull()), ErrIndexOutOfRange))
+	assert.True(t, errors.Is(mustPointer(t, "/a/x").Set(v, NewNull()), ErrPathInvalidSegment))
+	assert.True(t, errors.Is(mustPointer(t, "/x/y").Set(v, NewNull()), ErrKeyNotExist))
+	assert.True(t, errors.Is(mustPointer(t, "/a/0/y").Set(v, NewNull()), ErrPathTypeMismatch))
+
+	v.Freeze()
+	assert.True(t, errors.Is(mustPointer(t, "/a/0").Set(v, NewNull()), ErrValueFrozen))
+	assert.True(t, errors.Is(mustPointer(t, "/a/-").Set(v, NewNull()), ErrValueFrozen))
+	assert.True(t, errors.Is(mustPointer(t, "/b/e").Set(v, NewNull()), ErrValueFrozen))
+}
+
+func TestPointerDelete(t *testing.T) {
+	v := parseValue(t, "{\"a\":[1,2,3],\"b\":{\"c\":1,\"d\":2}}")
+	assert.Nil(t, mustPointer(t, "/a/1").Delete(v))
+	assert.Nil(t, mustPointer(t, "/b/c").Delete(v))
+	assert.Equal(t, "{\"a\":[1,3],\"b\":{\"d\":2}}", stringify(t, v))
+
+	assert.Equal(t, ErrPathEmpty, Pointer{}.Delete(v))
+	assert.True(t, errors.Is(mustPointer(t, "/a/-").Delete(v), ErrIndexOutOfRange))
+	assert.True(t, errors.Is(mustPointer(t, "/b/c").Delete(v), ErrKeyNotExist))
+
+	v.Freeze()
+	assert.True(t, errors.Is(mustPointer(t, "/a/0").Delete(v), ErrValueFrozen))
+	assert.True(t, errors.Is(mustPointer(t, "/b/d").Delete(v), ErrValueFrozen))
+}
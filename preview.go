@@ -0,0 +1,138 @@
+package goson
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// PreviewOptions bounds the output of Preview. A zero field means no limit.
+type PreviewOptions struct {
+	MaxBytes      int
+	MaxDepth      int
+	MaxArrayItems int
+	MaxStringLen  int
+}
+
+// Preview renders v as compact JSON-like text for logging. Containers
+// beyond MaxDepth, array elements or object members beyond MaxArrayItems,
+// and strings longer than MaxStringLen runes are replaced by elision
+// markers, and the output is cut to at most MaxBytes bytes, a final "…"
+// included. NaN and infinities are written as the literals NaN and
+// Infinity. Only the parts of v that are printed are visited.
+func Preview(v *Value, opts PreviewOptions) string {
+	pv := previewer{opts: opts}
+	pv.value(v, 0)
+	return pv.b.String()
+}
+
+type previewer struct {
+	opts PreviewOptions
+	b    strings.Builder
+	full bool
+}
+
+func (pv *previewer) write(s string) {
+	if pv.full {
+		return
+	}
+	if pv.opts.MaxBytes > 0 && pv.b.Len()+len(s) > pv.opts.MaxBytes {
+		// Cut so that the marker still fits within MaxBytes; this may
+		// reach back into what was already written.
+		out, mark := pv.b.String()+s, "…"
+		if pv.opts.MaxBytes < len(mark) {
+			mark = ""
+		}
+		n := pv.opts.MaxBytes - len(mark)
+		for n > 0 && !utf8.RuneStart(out[n]) {
+			n--
+		}
+		pv.b.Reset()
+		pv.b.WriteString(out[:n])
+		pv.b.WriteString(mark)
+		pv.full = true
+		return
+	}
+	pv.b.WriteString(s)
+}
+
+func (pv *previewer) value(v *Value, depth int) {
+	if pv.full {
+		return
+	}
+	switch v.t {
+	case STRING:
+		pv.string(v.str())
+	case ARRAY:
+		if len(v.arr()) != 0 && pv.opts.MaxDepth > 0 && depth >= pv.opts.MaxDepth {
+			pv.write("[\"…(" + strconv.Itoa(len(v.arr())) + " items)\"]")
+			return
+		}
+		pv.write("[")
+		for i, e := range v.arr() {
+			if pv.full {
+				return
+			}
+			if i > 0 {
+				pv.write(",")
+			}
+			if pv.opts.MaxArrayItems > 0 && i >= pv.opts.MaxArrayItems {
+				pv.write("\"…(+" + strconv.Itoa(len(v.arr())-i) + " items)\"")
+				break
+			}
+			pv.value(e, depth+1)
+		}
+		pv.write("]")
+	case OBJECT:
+		if len(v.obj()) != 0 && pv.opts.MaxDepth > 0 && depth >= pv.opts.MaxDepth {
+			pv.write("{\"…\":\"(" + strconv.Itoa(len(v.obj())) + " members)\"}")
+			return
+		}
+		pv.write("{")
+		for i, kv := range v.obj() {
+			if pv.full {
+				return
+			}
+			if i > 0 {
+				pv.write(",")
+			}
+			if pv.opts.MaxArrayItems > 0 && i >= pv.opts.MaxArrayItems {
+				pv.write("\"…\":\"(+" + strconv.Itoa(len(v.obj())-i) + " members)\"")
+				break
+			}
+			pv.string(kv.k)
+			pv.write(":")
+			pv.value(kv.v, depth+1)
+		}
+		pv.write("}")
+	case NUMBER:
+		s, _ := formatNumber(v.num(), NonFiniteLiteral)
+		pv.write(s)
+	default:
+		s, _ := v.stringifyValue()
+		pv.write(s)
+	}
+}
+
+func (pv *previewer) string(s string) {
+	max := pv.opts.MaxStringLen
+	if max > 0 && utf8.RuneCountInString(s) > max {
+		i, n := 0, 0
+		for n < max {
+			_, size := utf8.DecodeRuneInString(s[i:])
+			i += size
+			n++
+		}
+		q := stringifyString(s[:i])
+		pv.write(q[:len(q)-1] + "…(" + strconv.Itoa(len(s)) + " bytes)\"")
+		return
+	}
+	if pv.opts.MaxBytes > 0 && len(s) > pv.opts.MaxBytes {
+		n := pv.opts.MaxBytes
+		for n > 0 && !utf8.RuneStart(s[n]) {
+			n--
+		}
+		s = s[:n]
+	}
+	pv.write(stringifyString(s))
+}
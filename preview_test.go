@@ -0,0 +1,39 @@
+package goson
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestPreview(t *testing.T) {
+	var p Parser
+	s := "{\"a\":[1,2,3,4,5],\"s\":\"héllo world\",\"o\":{\"x\":{\"y\":[1]}},\"e\":[]}"
+	v, err := p.Parse(s)
+	assert.Nil(t, err)
+
+	assert.Equal(t, s, Preview(v, PreviewOptions{}))
+	assert.Equal(t, "{\"a\":[1,2,\"…(+3 items)\"],\"s\":\"héllo world\",\"…\":\"(+2 members)\"}",
+		Preview(v, PreviewOptions{MaxArrayItems: 2}))
+	assert.Equal(t, "{\"a\":[1,2,3,4,5],\"s\":\"héllo…(12 bytes)\",\"o\":{\"x\":{\"y\":[1]}},\"e\":[]}",
+		Preview(v, PreviewOptions{MaxStringLen: 5}))
+	assert.Equal(t, "{\"a\":[\"…(5 items)\"],\"s\":\"héllo world\",\"o\":{\"…\":\"(1 members)\"},\"e\":[]}",
+		Preview(v, PreviewOptions{MaxDepth: 1}))
+	assert.Equal(t, "{\"a\":[1,2,3,4,5],\"s\":…", Preview(v, PreviewOptions{MaxBytes: 24}))
+	assert.Equal(t, "{\"a\":[1,2,3,4,5],\"s\":\"h…", Preview(v, PreviewOptions{MaxBytes: 27}))
+	assert.Equal(t, "{\"a\":[1,2,3,4,5],\"s\":\"hé…", Preview(v, PreviewOptions{MaxBytes: 28}))
+	assert.Equal(t, "{…", Preview(v, PreviewOptions{MaxBytes: 4}))
+	assert.Equal(t, "{\"", Preview(v, PreviewOptions{MaxBytes: 2}))
+	assert.Equal(t, "{\"a\":[1,\"…(+4 items)\"],\"…\":\"(+3 members)\"}", Preview(v, PreviewOptions{MaxArrayItems: 1}))
+}
+
+func TestPreviewHuge(t *testing.T) {
+	var v Value
+	v.setArray(0)
+	for i := 0; i < 100000; i++ {
+		_ = v.insertArrayElement(NewString(strings.Repeat("x", 100)), i)
+	}
+	s := Preview(&v, PreviewOptions{MaxBytes: 64})
+	assert.True(t, len(s) <= 64)
+	assert.True(t, strings.HasSuffix(s, "…"))
+}
@@ -0,0 +1,71 @@
+package goson
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+// Scan implements sql.Scanner for JSON columns. SQL NULL scans as a JSON
+// null; use NullValue to tell the two apart.
+func (v *Value) Scan(src any) error {
+	if v.frozen {
+		return ErrValueFrozen
+	}
+	var s string
+	switch src := src.(type) {
+	case nil:
+		v.free()
+		return nil
+	case []byte:
+		s = string(src)
+	case string:
+		s = src
+	default:
+		return fmt.Errorf("cannot scan %T into goson.Value", src)
+	}
+	var p Parser
+	nv, err := p.Parse(s)
+	if err != nil {
+		return err
+	}
+	*v = *nv
+	return nil
+}
+
+// Value implements driver.Valuer, storing v as compact JSON text. A nil
+// *Value is stored as SQL NULL.
+func (v *Value) Value() (driver.Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	var e Encoder
+	return e.Encode(v)
+}
+
+// NullValue is a JSON column that may be SQL NULL, in the manner of
+// sql.NullString.
+type NullValue struct {
+	V     *Value
+	Valid bool
+}
+
+func (n *NullValue) Scan(src any) error {
+	if src == nil {
+		n.V, n.Valid = nil, false
+		return nil
+	}
+	var v Value
+	if err := v.Scan(src); err != nil {
+		n.V, n.Valid = nil, false
+		return err
+	}
+	n.V, n.Valid = &v, true
+	return nil
+}
+
+func (n NullValue) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.V.Value()
+}
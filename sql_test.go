@@ -0,0 +1,168 @@
+package goson
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"math"
+	"sync"
+	"testing"
+)
+
+type fakeDriver struct {
+	mu   sync.Mutex
+	rows []driver.Value
+}
+
+type fakeConn struct {
+	d *fakeDriver
+}
+
+type fakeStmt struct {
+	c     *fakeConn
+	query string
+}
+
+type fakeRows struct {
+	rows    []driver.Value
+	asBytes bool
+}
+
+var fakeDB = &fakeDriver{}
+
+func init() {
+	sql.Register("goson-fake", fakeDB)
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	return &fakeConn{d}, nil
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{c, query}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions not supported")
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	if s.query == "INSERT" {
+		return 1
+	}
+	return 0
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.c.d.mu.Lock()
+	defer s.c.d.mu.Unlock()
+	switch s.query {
+	case "INSERT":
+		s.c.d.rows = append(s.c.d.rows, args[0])
+	case "DELETE":
+		s.c.d.rows = nil
+	default:
+		return nil, errors.New("unknown statement " + s.query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.c.d.mu.Lock()
+	defer s.c.d.mu.Unlock()
+	rows := append([]driver.Value(nil), s.c.d.rows...)
+	return &fakeRows{rows, s.query == "SELECT BYTES"}, nil
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"doc"}
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	dest[0] = r.rows[0]
+	if s, ok := dest[0].(string); ok && r.asBytes {
+		dest[0] = []byte(s)
+	}
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestSQL(t *testing.T) {
+	db, err := sql.Open("goson-fake", "")
+	assert.Nil(t, err)
+	defer db.Close()
+	_, err = db.Exec("DELETE")
+	assert.Nil(t, err)
+
+	var nilValue *Value
+	for _, arg := range []any{
+		parseValue(t, "{\"a\":[1,\"x\"]}"),
+		NewNull(),
+		nilValue,
+		NullValue{V: NewBool(true), Valid: true},
+		NullValue{},
+	} {
+		_, err = db.Exec("INSERT", arg)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, []driver.Value{"{\"a\":[1,\"x\"]}", "null", nil, "true", nil}, fakeDB.rows)
+
+	for _, query := range []string{"SELECT", "SELECT BYTES"} {
+		rows, err := db.Query(query)
+		assert.Nil(t, err)
+		var values []string
+		var nulls []NullValue
+		for rows.Next() {
+			var v Value
+			assert.Nil(t, rows.Scan(&v))
+			values = append(values, stringify(t, &v))
+		}
+		assert.Nil(t, rows.Close())
+		assert.Equal(t, []string{"{\"a\":[1,\"x\"]}", "null", "null", "true", "null"}, values)
+
+		rows, err = db.Query(query)
+		assert.Nil(t, err)
+		for rows.Next() {
+			var n NullValue
+			assert.Nil(t, rows.Scan(&n))
+			nulls = append(nulls, n)
+		}
+		assert.Nil(t, rows.Close())
+		assert.Equal(t, 5, len(nulls))
+		assert.True(t, nulls[1].Valid)
+		assert.Equal(t, NULL, nulls[1].V.t)
+		assert.False(t, nulls[2].Valid)
+		assert.Nil(t, nulls[2].V)
+		assert.True(t, nulls[3].Valid)
+		assert.Equal(t, TRUE, nulls[3].V.t)
+	}
+}
+
+func TestSQLError(t *testing.T) {
+	var v Value
+	assert.EqualError(t, v.Scan(42), "cannot scan int into goson.Value")
+	assert.Equal(t, ErrParseMissCommaOrSquareBracket, v.Scan("[1"))
+	var n NullValue
+	assert.Equal(t, ErrParseInvalidValue, n.Scan([]byte("nope")))
+	assert.False(t, n.Valid)
+
+	_, err := NewNumber(math.NaN()).Value()
+	assert.Equal(t, ErrNumberNotFinite, err)
+}
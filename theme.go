@@ -0,0 +1,59 @@
+package goson
+
+import "os"
+
+// Theme holds an ANSI SGR parameter string, such as "1;34", for each kind
+// of token. An empty style leaves that kind of token uncolored.
+type Theme struct {
+	Key    string
+	String string
+	Number string
+	Bool   string
+	Null   string
+	Punct  string
+}
+
+// DefaultTheme returns a new copy of the default colors, which the caller
+// may change freely.
+func DefaultTheme() *Theme {
+	return &Theme{
+		Key:    "1;34",
+		String: "32",
+		Number: "36",
+		Bool:   "33",
+		Null:   "1;30",
+		Punct:  "",
+	}
+}
+
+var plainTheme Theme
+
+// noColor reports whether the NO_COLOR environment variable asks for plain
+// output.
+func noColor() bool {
+	return os.Getenv("NO_COLOR") != ""
+}
+
+// For returns t when colored output is wanted, and nil when the output is
+// not a terminal or the NO_COLOR environment variable is set.
+func (t *Theme) For(terminal bool) *Theme {
+	if !terminal || noColor() {
+		return nil
+	}
+	return t
+}
+
+func (t *Theme) scalar(typ Type) string {
+	switch typ {
+	case NULL:
+		return t.Null
+	case FALSE, TRUE:
+		return t.Bool
+	case NUMBER:
+		return t.Number
+	case STRING:
+		return t.String
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,65 @@
+package goson
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestEncodeTheme(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	theme := Theme{Key: "k", String: "s", Number: "n", Bool: "b", Null: "z", Punct: "p"}
+	e := Encoder{Theme: &theme}
+	assert.Equal(t,
+		"\x1b[pm[\x1b[0m\x1b[nm1\x1b[0m\x1b[pm,\x1b[0m\x1b[bmtrue\x1b[0m\x1b[pm,\x1b[0m\x1b[zmnull\x1b[0m\x1b[pm]\x1b[0m",
+		encodeString(t, e, "[1,true,null]"))
+	assert.Equal(t,
+		"\x1b[pm{\x1b[0m\x1b[km\"a\"\x1b[0m\x1b[pm:\x1b[0m\x1b[sm\"b\"\x1b[0m\x1b[pm}\x1b[0m",
+		encodeString(t, e, "{\"a\":\"b\"}"))
+}
+
+func TestEncodeThemeLayout(t *testing.T) {
+	s := "{\"abc\":[1,2,3],\"d\":{\"e\":false}}"
+	for _, width := range []int{0, 10, 40} {
+		plain := Encoder{Indent: "  ", Width: width}
+		colored := Encoder{Indent: "  ", Width: width, Theme: DefaultTheme()}
+		assert.Equal(t, encodeString(t, plain, s), stripANSI(encodeString(t, colored, s)))
+	}
+}
+
+func TestThemeFor(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	theme := DefaultTheme()
+	assert.Same(t, theme, theme.For(true))
+	assert.Nil(t, theme.For(false))
+	t.Setenv("NO_COLOR", "1")
+	assert.Nil(t, theme.For(true))
+}
+
+func TestEncodeNoColor(t *testing.T) {
+	e := Encoder{Theme: DefaultTheme()}
+	t.Setenv("NO_COLOR", "")
+	assert.NotEqual(t, "[1]", encodeString(t, e, "[1]"))
+	t.Setenv("NO_COLOR", "1")
+	assert.Equal(t, "[1]", encodeString(t, e, "[1]"))
+}
+
+func TestDefaultTheme(t *testing.T) {
+	theme := DefaultTheme()
+	theme.Key = ""
+	assert.Equal(t, "1;34", DefaultTheme().Key)
+	assert.NotSame(t, DefaultTheme(), DefaultTheme())
+}
+
+func stripANSI(s string) string {
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\x1b' {
+			for s[i] != 'm' {
+				i++
+			}
+			continue
+		}
+		b = append(b, s[i])
+	}
+	return string(b)
+}
@@ -17,6 +17,27 @@ const (
 	OBJECT
 )
 
+func (t Type) String() string {
+	switch t {
+	case NULL:
+		return "null"
+	case FALSE:
+		return "false"
+	case TRUE:
+		return "true"
+	case NUMBER:
+		return "number"
+	case STRING:
+		return "string"
+	case ARRAY:
+		return "array"
+	case OBJECT:
+		return "object"
+	default:
+		return "Type(" + strconv.Itoa(int(t)) + ")"
+	}
+}
+
 var (
 	ErrParseInvalidValue             = errors.New("parse invalid value")
 	ErrParseRootNotSingular          = errors.New("parse root not singular")
@@ -31,4 +52,21 @@ var (
 	ErrParseMissColon                = errors.New("parse miss colon")
 	ErrParseMissCommaOrCurlyBracket  = errors.New("parse miss comma or curly bracket")
 	ErrKeyNotExist                   = errors.New("key not exist")
+	ErrKeyExist                      = errors.New("key exist")
+	ErrWriteMissKey                  = errors.New("write miss key")
+	ErrWriteMissValue                = errors.New("write miss value")
+	ErrWriteUnexpectedKey            = errors.New("write unexpected key")
+	ErrWriteMismatchedEnd            = errors.New("write mismatched end")
+	ErrWriteRootNotSingular          = errors.New("write root not singular")
+	ErrWriteIncomplete               = errors.New("write incomplete")
+	ErrNumberNotFinite               = errors.New("number not finite")
+	ErrNumberInexact                 = errors.New("number inexact")
+	ErrEncodeCycle                   = errors.New("encode cycle")
+	ErrIndexOutOfRange               = errors.New("index out of range")
+	ErrPathTypeMismatch              = errors.New("path type mismatch")
+	ErrPathInvalidSegment            = errors.New("path invalid segment")
+	ErrPathEmpty                     = errors.New("path empty")
+	ErrPointerInvalid                = errors.New("pointer invalid")
+	ErrDecodeInvalidTarget           = errors.New("decode invalid target")
+	ErrValueFrozen                   = errors.New("value frozen")
 )
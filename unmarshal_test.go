@@ -0,0 +1,184 @@
+package goson
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+type upper string
+
+func (u *upper) UnmarshalGoson(v *Value) error {
+	s, err := v.getString()
+	if err != nil {
+		return err
+	}
+	*u = upper(strings.ToUpper(s))
+	return nil
+}
+
+type level int
+
+func (l *level) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return errors.New("unknown level " + string(text))
+	}
+	return nil
+}
+
+type Base struct {
+	ID      int `json:"id"`
+	Created time.Time
+}
+
+type Meta struct {
+	Tags []string
+	ID   string
+}
+
+type user struct {
+	Base
+	*Meta
+	Name    string            `json:"name"`
+	Age     int               `json:"age,omitempty"`
+	Email   *string           `json:"email"`
+	Scores  [3]float64        `json:"scores"`
+	Attrs   map[string]any    `json:"attrs"`
+	Limits  map[int]uint8     `json:"limits"`
+	Levels  map[level]bool    `json:"levels"`
+	Nick    upper             `json:"nick"`
+	Level   level             `json:"level"`
+	Ignored string            `json:"-"`
+	Friends []*user           `json:"friends"`
+	Extra   map[string]string `json:"extra"`
+	private int
+}
+
+func TestUnmarshal(t *testing.T) {
+	data := `{
+		"id": 7,
+		"Created": "2024-01-02T03:04:05Z",
+		"tags": ["a", "b"],
+		"name": "ann",
+		"AGE": 30,
+		"email": "ann@example.com",
+		"scores": [1, 2.5, 3],
+		"attrs": {"x": [1, null]},
+		"limits": {"1": 2, "-3": 4},
+		"levels": {"low": true},
+		"nick": "annie",
+		"level": "high",
+		"-": "no",
+		"friends": [{"name": "bob", "friends": null}, null],
+		"extra": null,
+		"private": 1,
+		"unknown": true
+	}`
+	var u user
+	assert.Nil(t, Unmarshal([]byte(data), &u))
+	assert.Equal(t, 7, u.Base.ID)
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), u.Created)
+	assert.Equal(t, []string{"a", "b"}, u.Tags)
+	assert.Equal(t, "", u.Meta.ID)
+	assert.Equal(t, "ann", u.Name)
+	assert.Equal(t, 30, u.Age)
+	assert.Equal(t, "ann@example.com", *u.Email)
+	assert.Equal(t, [3]float64{1, 2.5, 3}, u.Scores)
+	assert.Equal(t, map[string]any{"x": []any{1.0, nil}}, u.Attrs)
+	assert.Equal(t, map[int]uint8{1: 2, -3: 4}, u.Limits)
+	assert.Equal(t, map[level]bool{1: true}, u.Levels)
+	assert.Equal(t, upper("ANNIE"), u.Nick)
+	assert.Equal(t, level(2), u.Level)
+	assert.Equal(t, "", u.Ignored)
+	assert.Equal(t, 2, len(u.Friends))
+	assert.Equal(t, "bob", u.Friends[0].Name)
+	assert.Nil(t, u.Friends[1])
+	assert.Nil(t, u.Extra)
+	assert.Equal(t, 0, u.private)
+}
+
+func TestUnmarshalNull(t *testing.T) {
+	type inner struct{ A int }
+	x := struct {
+		N int
+		S string
+		I inner
+		P *int
+		L []int
+	}{N: 1, S: "s", I: inner{2}, P: new(int), L: []int{3}}
+	assert.Nil(t, Unmarshal([]byte("{\"N\":null,\"S\":null,\"I\":null,\"P\":null,\"L\":null}"), &x))
+	assert.Equal(t, 1, x.N)
+	assert.Equal(t, "s", x.S)
+	assert.Equal(t, inner{2}, x.I)
+	assert.Nil(t, x.P)
+	assert.Nil(t, x.L)
+
+	i := inner{4}
+	assert.Nil(t, Unmarshal([]byte("null"), &i))
+	assert.Equal(t, inner{4}, i)
+}
+
+func TestUnmarshalOptions(t *testing.T) {
+	type config struct {
+		Name string `json:"name"`
+	}
+	var c config
+	assert.Nil(t, Unmarshal([]byte(`{"NAME":"a"}`), &c))
+	assert.Equal(t, "a", c.Name)
+
+	c = config{}
+	assert.Nil(t, DecodeOptions{CaseSensitive: true}.Unmarshal([]byte(`{"NAME":"a"}`), &c))
+	assert.Equal(t, "", c.Name)
+
+	err := DecodeOptions{DisallowUnknownFields: true}.Unmarshal([]byte(`{"name":"a","other":1}`), &c)
+	assert.EqualError(t, err, "/other: unknown field \"other\"")
+}
+
+func TestUnmarshalError(t *testing.T) {
+	type account struct {
+		Users []struct {
+			Age int `json:"age"`
+		} `json:"users"`
+	}
+	var a account
+	err := Unmarshal([]byte(`{"users":[{"age":1},{},{"age":2},{"age":"old"}]}`), &a)
+	assert.EqualError(t, err, "/users/3/age: cannot decode string into int")
+
+	err = Unmarshal([]byte(`{"users":[}`), &a)
+	assert.Equal(t, ErrParseInvalidValue, err)
+	assert.Equal(t, ErrDecodeInvalidTarget, Unmarshal([]byte(`{}`), a))
+	assert.Equal(t, ErrDecodeInvalidTarget, Unmarshal([]byte(`{}`), nil))
+
+	var u user
+	err = Unmarshal([]byte(`{"nick":1}`), &u)
+	assert.EqualError(t, err, "/nick: value type is not string")
+	err = Unmarshal([]byte(`{"level":"mid"}`), &u)
+	assert.EqualError(t, err, "/level: unknown level mid")
+	err = Unmarshal([]byte(`{"limits":{"x":1}}`), &u)
+	assert.EqualError(t, err, "/limits/x: cannot decode key \"x\" into int")
+}
+
+func TestUnmarshalAmbiguousFields(t *testing.T) {
+	type A struct{ X, Y int }
+	type B struct {
+		X int
+		Y int `json:"Y"`
+	}
+	type C struct {
+		A
+		B
+	}
+	var c C
+	assert.Nil(t, Unmarshal([]byte(`{"X":1,"Y":2}`), &c))
+	assert.Equal(t, 0, c.A.X)
+	assert.Equal(t, 0, c.B.X)
+	assert.Equal(t, 0, c.A.Y)
+	assert.Equal(t, 2, c.B.Y)
+}
@@ -2,14 +2,42 @@ package goson
 
 import (
 	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+	"unsafe"
 )
 
+// Value is a tagged union of 32 bytes. What p and u hold depends on t:
+//
+//	NUMBER  u is the bits of the float64
+//	STRING  p points at the bytes and u is the length
+//	ARRAY   p points at the first element of the backing array, and u packs
+//	        the length in its low and the capacity in its high 32 bits
+//	OBJECT  like ARRAY, for the member list
+//
+// Keeping the lists inline, rather than behind a pointer, means a Value
+// copied by assignment does not share its list header with the original,
+// just as with the plain slices used before. idx holds the *keyIndex of a
+// large object and is only accessed atomically; see findKey. arena marks
+// a node parsed into an Arena, whose string and key bytes live in the
+// arena and must be cloned before they may outlive it.
+//
+// Array elements stay *Value rather than being stored inline: callers
+// hold on to the pointers returned by getArrayElement, Lookup and
+// friends, and those must not move when the array grows or is
+// rearranged. The accessors below are the only code that knows about this
+// layout.
 type Value struct {
-	s string
-	n float64
-	a []*Value
-	o []*KV
-	t Type
+	p      unsafe.Pointer
+	u      uint64
+	idx    unsafe.Pointer
+	t      Type
+	frozen bool
+	arena  bool
 }
 
 type KV struct {
@@ -17,42 +45,161 @@ type KV struct {
 	v *Value
 }
 
-func (v *Value) setNull() {
-	v.s = ""
+func (v *Value) str() string {
+	if v.t != STRING {
+		return ""
+	}
+	return unsafe.String((*byte)(v.p), int(v.u))
+}
+
+func (v *Value) num() float64 {
+	if v.t != NUMBER {
+		return 0
+	}
+	return math.Float64frombits(v.u)
+}
+
+func (v *Value) arr() []*Value {
+	if v.t != ARRAY || v.p == nil {
+		return nil
+	}
+	return unsafe.Slice((**Value)(v.p), int(v.u>>32))[:uint32(v.u)]
+}
+
+func (v *Value) obj() []*KV {
+	if v.t != OBJECT || v.p == nil {
+		return nil
+	}
+	return unsafe.Slice((**KV)(v.p), int(v.u>>32))[:uint32(v.u)]
+}
+
+// keyIndex maps the keys of a large object to the position of their first
+// member. It records the member list it was built for, so an index that a
+// Value inherited by assignment, or one left behind by a list that has
+// since moved, is recognised as stale and not used.
+type keyIndex struct {
+	m    map[string]int
+	data unsafe.Pointer
+	n    int
+}
+
+// keyIndex returns the index of v if it is current. The index is read and
+// published atomically, since findKey builds it lazily and concurrent
+// readers of the same object may race to do so.
+func (v *Value) keyIndex() *keyIndex {
+	ix := (*keyIndex)(atomic.LoadPointer(&v.idx))
+	if ix == nil || v.t != OBJECT || ix.data != v.p || ix.n != int(uint32(v.u)) {
+		return nil
+	}
+	return ix
+}
+
+func (v *Value) index() map[string]int {
+	if ix := v.keyIndex(); ix != nil {
+		return ix.m
+	}
+	return nil
+}
+
+func (v *Value) storeString(s string) {
+	v.t = STRING
+	v.p = unsafe.Pointer(unsafe.StringData(s))
+	v.u = uint64(len(s))
+}
+
+func (v *Value) storeNumber(n float64) {
+	v.t = NUMBER
+	v.p = nil
+	v.u = math.Float64bits(n)
+}
+
+// storeList packs the data pointer, length and capacity of a slice into
+// v.p and v.u.
+func (v *Value) storeList(data unsafe.Pointer, n, c int) {
+	if uint64(c) > math.MaxUint32 {
+		panic("goson: array or object too large")
+	}
+	v.p = data
+	v.u = uint64(n) | uint64(c)<<32
+}
+
+func (v *Value) storeArray(a []*Value) {
+	v.t = ARRAY
+	v.storeList(unsafe.Pointer(unsafe.SliceData(a)), len(a), cap(a))
+}
+
+func (v *Value) storeObject(o []*KV) {
+	v.t = OBJECT
+	v.storeList(unsafe.Pointer(unsafe.SliceData(o)), len(o), cap(o))
+}
+
+func (v *Value) setIndex(idx map[string]int) {
+	var ix *keyIndex
+	if idx != nil {
+		ix = &keyIndex{idx, v.p, len(v.obj())}
+	}
+	atomic.StorePointer(&v.idx, unsafe.Pointer(ix))
+}
+
+// moveIndex makes ix, the index of v before its member list changed,
+// current for the new list. Only a mutator, which owns v, may call it.
+func (v *Value) moveIndex(ix *keyIndex) {
+	ix.data = v.p
+	ix.n = len(v.obj())
+}
+
+var objectIndexThreshold = 8
+
+func (v *Value) setNull() error {
+	if v.frozen {
+		return ErrValueFrozen
+	}
+	v.reset()
+	return nil
+}
+
+func (v *Value) reset() {
+	v.p = nil
+	v.u = 0
+	v.idx = nil
 	v.t = NULL
 }
 
 func (v *Value) free() {
 	switch v.t {
-	case STRING:
-		v.s = ""
 	case ARRAY:
-		for _, e := range v.a {
-			e.free()
+		for _, e := range v.arr() {
+			if !e.frozen {
+				e.free()
+			}
 		}
-		v.a = make([]*Value, 0, 0)
 	case OBJECT:
-		for _, kv := range v.o {
-			kv.k = ""
-			kv.v.free()
+		for _, kv := range v.obj() {
+			if !kv.v.frozen {
+				kv.k = ""
+				kv.v.free()
+			}
 		}
-		v.o = make([]*KV, 0, 0)
 	default:
 	}
-	v.setNull()
+	v.reset()
 }
 
 func (v *Value) getType() Type {
 	return v.t
 }
 
-func (v *Value) setBoolean(b bool) {
+func (v *Value) setBoolean(b bool) error {
+	if v.frozen {
+		return ErrValueFrozen
+	}
 	v.free()
 	if b {
 		v.t = TRUE
 	} else {
 		v.t = FALSE
 	}
+	return nil
 }
 
 func (v *Value) getBoolean() (bool, error) {
@@ -62,169 +209,301 @@ func (v *Value) getBoolean() (bool, error) {
 	return v.t == TRUE, nil
 }
 
-func (v *Value) setNumber(n float64) {
+func (v *Value) setNumber(n float64) error {
+	if v.frozen {
+		return ErrValueFrozen
+	}
 	v.free()
-	v.t = NUMBER
-	v.n = n
+	v.storeNumber(n)
+	return nil
+}
+
+func (v *Value) setFiniteNumber(n float64) error {
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		return ErrNumberNotFinite
+	}
+	return v.setNumber(n)
 }
 
 func (v *Value) getNumber() (float64, error) {
 	if v.t != NUMBER {
 		return 0, fmt.Errorf("value type is not number")
 	}
-	return v.n, nil
+	return v.num(), nil
 }
 
-func (v *Value) setString(s string) {
+func (v *Value) setString(s string) error {
+	if v.frozen {
+		return ErrValueFrozen
+	}
 	v.free()
-	v.t = STRING
-	v.s = s
+	v.storeString(s)
+	return nil
 }
 
 func (v *Value) getString() (string, error) {
 	if v.t != STRING {
 		return "", fmt.Errorf("value type is not string")
 	}
-	return v.s, nil
+	return v.str(), nil
 }
 
-func (v *Value) setArray(size int) {
+func (v *Value) setArray(size int) error {
+	if v.frozen {
+		return ErrValueFrozen
+	}
 	v.free()
-	v.t = ARRAY
-	v.a = make([]*Value, 0, size)
+	v.storeArray(make([]*Value, 0, size))
+	return nil
 }
 
 func (v *Value) getArrayElement(index int) (*Value, error) {
 	if v.t != ARRAY {
 		return &Value{}, fmt.Errorf("value type is not array")
 	}
-	if index >= len(v.a) {
+	a := v.arr()
+	if index >= len(a) {
 		return &Value{}, fmt.Errorf("index out of range")
 	}
-	return v.a[index], nil
+	return a[index], nil
 }
 
 func (v *Value) insertArrayElement(e *Value, index int) error {
+	if v.frozen {
+		return ErrValueFrozen
+	}
 	if v.t != ARRAY {
 		return fmt.Errorf("value type is not array")
 	}
-	if index > len(v.a) {
+	a := v.arr()
+	if index > len(a) {
 		return fmt.Errorf("index out of range")
 	}
-	v.a = append(v.a, e)
-	copy(v.a[index+1:], v.a[index:])
-	v.a[index] = e
+	a = append(a, e)
+	copy(a[index+1:], a[index:])
+	a[index] = e
+	v.storeArray(a)
 	return nil
 }
 
 func (v *Value) eraseArrayElement(index, count int) error {
+	if v.frozen {
+		return ErrValueFrozen
+	}
 	if v.t != ARRAY {
 		return fmt.Errorf("value type is not array")
 	}
-	if index+count > len(v.a) {
+	a := v.arr()
+	if index+count > len(a) {
 		return fmt.Errorf("index out of range")
 	}
 	if count == 0 {
 		return nil
 	}
-	v.a = append(v.a[:index], v.a[index+count:]...)
+	v.storeArray(append(a[:index], a[index+count:]...))
 	return nil
 }
 
 func (v *Value) clearArray() error {
-	return v.eraseArrayElement(0, len(v.a))
+	return v.eraseArrayElement(0, len(v.arr()))
 }
 
-func (v *Value) setObject(size int) {
+func (v *Value) setObject(size int) error {
+	if v.frozen {
+		return ErrValueFrozen
+	}
 	v.free()
-	v.t = OBJECT
-	v.o = make([]*KV, 0, size)
+	v.storeObject(make([]*KV, 0, size))
+	return nil
+}
+
+// findKey returns the position of the first member named key, or -1. Once
+// an object has objectIndexThreshold members the lookup goes through a
+// key to position map, which is built here on first use and kept up to
+// date by the mutators below. Building it is safe while other goroutines
+// read v too.
+func (v *Value) findKey(key string) int {
+	o := v.obj()
+	idx := v.index()
+	if idx == nil && len(o) >= objectIndexThreshold {
+		idx = v.buildIndex()
+	}
+	if idx != nil {
+		if i, ok := idx[key]; ok {
+			return i
+		}
+		return -1
+	}
+	for i, kv := range o {
+		if kv.k == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func (v *Value) buildIndex() map[string]int {
+	o := v.obj()
+	idx := make(map[string]int, len(o))
+	for i, kv := range o {
+		if _, ok := idx[kv.k]; !ok {
+			idx[kv.k] = i
+		}
+	}
+	v.setIndex(idx)
+	return idx
 }
 
 func (v *Value) getObjectValue(key string) (*Value, error) {
 	if v.t != OBJECT {
 		return &Value{}, fmt.Errorf("value type is not object")
 	}
-	for _, kv := range v.o {
-		if kv.k == key {
-			return kv.v, nil
-		}
+	if i := v.findKey(key); i >= 0 {
+		return v.obj()[i].v, nil
 	}
 	return &Value{}, ErrKeyNotExist
 }
 
 func (v *Value) setObjectValue(key string, value *Value) error {
+	if v.frozen {
+		return ErrValueFrozen
+	}
 	if v.t != OBJECT {
 		return fmt.Errorf("value type is not object")
 	}
-	for _, kv := range v.o {
-		if kv.k == key {
-			kv.v = value
-			return nil
-		}
+	if i := v.findKey(key); i >= 0 {
+		v.obj()[i].v = value
+		return nil
+	}
+	ix := v.keyIndex()
+	o := append(v.obj(), &KV{key, value})
+	v.storeObject(o)
+	if ix != nil {
+		ix.m[key] = len(o) - 1
+		v.moveIndex(ix)
 	}
-	v.o = append(v.o, &KV{key, value})
 	return nil
 }
 
 func (v *Value) removeObjectValue(key string) error {
+	if v.frozen {
+		return ErrValueFrozen
+	}
 	if v.t != OBJECT {
 		return fmt.Errorf("value type is not object")
 	}
-	var index int
-	for i, kv := range v.o {
-		if kv.k == key {
-			index = i
-			break
+	index := v.findKey(key)
+	if index < 0 {
+		return ErrKeyNotExist
+	}
+	ix := v.keyIndex()
+	o := v.obj()
+	o = append(o[:index], o[index+1:]...)
+	v.storeObject(o)
+	if ix != nil {
+		v.moveIndex(ix)
+		idx := ix.m
+		delete(idx, key)
+		for i := index; i < len(o); i++ {
+			k := o[i].k
+			if j, ok := idx[k]; ok && j == i+1 {
+				idx[k] = i
+			} else if !ok && k == key {
+				idx[k] = i
+			}
 		}
 	}
-	v.o = append(v.o[:index], v.o[index+1:]...)
 	return nil
 }
 
 func (v *Value) clearObject() error {
+	if v.frozen {
+		return ErrValueFrozen
+	}
 	if v.t != OBJECT {
 		return fmt.Errorf("value type is not object")
 	}
-	v.o = v.o[0:0]
+	v.storeObject(v.obj()[0:0])
+	v.setIndex(nil)
 	return nil
 }
 
 func stringifyString(s string) string {
-	result := ""
+	var b strings.Builder
+	b.Grow(len(s) + 2)
+	_ = writeString(&b, s)
+	return b.String()
+}
+
+// stringWriter is implemented by strings.Builder and bufio.Writer.
+type stringWriter interface {
+	io.ByteWriter
+	io.StringWriter
+}
 
-	result += "\""
-	for _, ch := range s {
-		switch ch {
+// writeString writes s to w as a quoted JSON string. Runs of characters
+// that need no escaping are written in one piece, so the cost is linear
+// in len(s). Invalid UTF-8 is replaced by U+FFFD.
+func writeString(w stringWriter, s string) error {
+	const hex = "0123456789ABCDEF"
+	if err := w.WriteByte('"'); err != nil {
+		return err
+	}
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= utf8.RuneSelf {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			if r != utf8.RuneError || size != 1 {
+				i += size
+				continue
+			}
+		} else if c >= 0x20 && c != '"' && c != '\\' {
+			i++
+			continue
+		}
+		if _, err := w.WriteString(s[start:i]); err != nil {
+			return err
+		}
+		var esc string
+		switch c {
 		case '"':
-			result += "\\\""
+			esc = "\\\""
 		case '\\':
-			result += "\\\\"
+			esc = "\\\\"
 		case '\b':
-			result += "\\b"
+			esc = "\\b"
 		case '\f':
-			result += "\\f"
+			esc = "\\f"
 		case '\n':
-			result += "\\n"
+			esc = "\\n"
 		case '\r':
-			result += "\\r"
+			esc = "\\r"
 		case '\t':
-			result += "\\t"
+			esc = "\\t"
 		default:
-			if uint(ch) < 0x20 {
-				buf := fmt.Sprintf("\\u%04X", ch)
-				result += buf
+			if c < 0x20 {
+				esc = "\\u00" + string(hex[c>>4]) + string(hex[c&0xF])
 			} else {
-				result += string(ch)
+				esc = "\uFFFD"
 			}
 		}
+		if _, err := w.WriteString(esc); err != nil {
+			return err
+		}
+		i++
+		start = i
 	}
-	result += "\""
-
-	return result
+	if _, err := w.WriteString(s[start:]); err != nil {
+		return err
+	}
+	return w.WriteByte('"')
 }
 
-func (v *Value) stringifyValue() string {
+// stringifyValue renders v as compact JSON. Like the default Encoder, it
+// fails with ErrNumberNotFinite on NaN and infinities, which JSON cannot
+// represent.
+func (v *Value) stringifyValue() (string, error) {
 	s := ""
 	switch v.t {
 	case NULL:
@@ -234,36 +513,76 @@ func (v *Value) stringifyValue() string {
 	case TRUE:
 		s += "true"
 	case NUMBER:
-		s += fmt.Sprintf("%.17g", v.n)
+		n, err := formatNumber(v.num(), NonFiniteError)
+		if err != nil {
+			return "", err
+		}
+		s += n
 	case STRING:
-		s += stringifyString(v.s)
+		s += stringifyString(v.str())
 	case ARRAY:
 		s += "["
-		for i, e := range v.a {
+		for i, e := range v.arr() {
 			if i > 0 {
 				s += ","
 			}
-			s += e.stringifyValue()
+			es, err := e.stringifyValue()
+			if err != nil {
+				return "", err
+			}
+			s += es
 		}
 		s += "]"
 	case OBJECT:
 		s += "{"
-		for i, kv := range v.o {
+		for i, kv := range v.obj() {
 			if i > 0 {
 				s += ","
 			}
 			s += stringifyString(kv.k)
 			s += ":"
-			s += kv.v.stringifyValue()
+			vs, err := kv.v.stringifyValue()
+			if err != nil {
+				return "", err
+			}
+			s += vs
 		}
 		s += "}"
 	default:
 		panic("invalid value type")
 	}
-	return s
+	return s, nil
+}
+
+// Clone returns a deep copy of v that shares no memory with it, string
+// bytes included. Use it to keep a Value parsed into an Arena past the
+// arena's Reset.
+func (v *Value) Clone() *Value {
+	return v.clone(true)
 }
 
+// copy returns a deep copy of v. Strings are immutable, so they are
+// shared with v unless they live in an arena.
 func (v *Value) copy() *Value {
+	return v.clone(false)
+}
+
+// ownString returns s, a string or key of v, cloned if it lives in an
+// arena so that it can be handed out safely.
+func (v *Value) ownString(s string) string {
+	if v.arena {
+		return strings.Clone(s)
+	}
+	return s
+}
+
+func (v *Value) clone(deep bool) *Value {
+	own := func(s string) string {
+		if deep {
+			return strings.Clone(s)
+		}
+		return v.ownString(s)
+	}
 	var result Value
 	switch v.t {
 	case FALSE:
@@ -271,22 +590,182 @@ func (v *Value) copy() *Value {
 	case TRUE:
 		result.setBoolean(true)
 	case STRING:
-		result.setString(v.s)
+		result.setString(own(v.str()))
 	case NUMBER:
-		result.setNumber(v.n)
+		result.setNumber(v.num())
 	case ARRAY:
-		result.setArray(len(v.a))
-		for i := 0; i < len(v.a); i++ {
-			_ = result.insertArrayElement(v.a[i].copy(), i)
+		a := v.arr()
+		result.setArray(len(a))
+		for i := 0; i < len(a); i++ {
+			_ = result.insertArrayElement(a[i].clone(deep), i)
 		}
 	case OBJECT:
-		result.setObject(len(v.o))
-		for i := 0; i < len(v.o); i++ {
-			value := v.o[i].v.copy()
-			_ = result.setObjectValue(v.o[i].k, value)
+		// Members are copied one for one, repeated keys included, so that
+		// the copy stays equal to v.
+		o := make([]*KV, len(v.obj()))
+		for i, kv := range v.obj() {
+			o[i] = &KV{own(kv.k), kv.v.clone(deep)}
 		}
+		result.storeObject(o)
 	default:
 	}
 
 	return &result
 }
+
+// shallowCopy returns a mutable copy of the array or object v that shares
+// v's elements and member values, but not its lists or members, so it can
+// be changed without affecting v.
+func (v *Value) shallowCopy() *Value {
+	var nv Value
+	switch v.t {
+	case ARRAY:
+		nv.storeArray(append([]*Value(nil), v.arr()...))
+	case OBJECT:
+		o := make([]*KV, len(v.obj()))
+		for i, kv := range v.obj() {
+			o[i] = &KV{kv.k, kv.v}
+		}
+		nv.storeObject(o)
+		nv.arena = v.arena
+	default:
+		return v.copy()
+	}
+	return &nv
+}
+
+func NewNull() *Value {
+	return &Value{}
+}
+
+func NewBool(b bool) *Value {
+	var v Value
+	v.setBoolean(b)
+	return &v
+}
+
+func NewNumber(n float64) *Value {
+	var v Value
+	v.setNumber(n)
+	return &v
+}
+
+// NewFiniteNumber is NewNumber for callers that want NaN and infinities
+// rejected up front, with ErrNumberNotFinite, rather than when encoding.
+func NewFiniteNumber(n float64) (*Value, error) {
+	var v Value
+	if err := v.setFiniteNumber(n); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func NewString(s string) *Value {
+	var v Value
+	v.setString(s)
+	return &v
+}
+
+func NewArray(elems ...*Value) *Value {
+	var v Value
+	v.setArray(len(elems))
+	v.storeArray(append(v.arr(), elems...))
+	return &v
+}
+
+func NewKV(k string, v *Value) *KV {
+	return &KV{k, v}
+}
+
+func NewObject(members ...*KV) *Value {
+	var v Value
+	v.setObject(len(members))
+	for _, kv := range members {
+		_ = v.setObjectValue(kv.k, kv.v)
+	}
+	return &v
+}
+
+// String returns v as compact JSON. A Value that cannot be encoded, such
+// as one holding NaN, prints as a fmt style error marker instead.
+func (v *Value) String() string {
+	if v == nil {
+		return "<nil>"
+	}
+	return v.text(Encoder{}, 's')
+}
+
+func (v *Value) text(e Encoder, verb rune) string {
+	s, err := e.Encode(v)
+	if err != nil {
+		return "%!" + string(verb) + "(goson.Value: " + err.Error() + ")"
+	}
+	return s
+}
+
+// Format prints compact JSON for %v and %s, indented JSON for %+v, and a
+// Go expression built from the New* constructors for %#v.
+func (v *Value) Format(f fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && f.Flag('#'):
+		if v == nil {
+			io.WriteString(f, "(*goson.Value)(nil)")
+			return
+		}
+		io.WriteString(f, v.goString())
+	case verb == 'v' && f.Flag('+') && v != nil:
+		io.WriteString(f, v.text(Encoder{Indent: "  "}, verb))
+	case (verb == 'v' || verb == 's') && v != nil:
+		io.WriteString(f, v.text(Encoder{}, verb))
+	case verb == 'v' || verb == 's':
+		io.WriteString(f, v.String())
+	case verb == 'q':
+		io.WriteString(f, strconv.Quote(v.String()))
+	default:
+		fmt.Fprintf(f, "%%!%c(*goson.Value=%s)", verb, v.String())
+	}
+}
+
+func (v *Value) goString() string {
+	switch v.t {
+	case NULL:
+		return "goson.NewNull()"
+	case FALSE:
+		return "goson.NewBool(false)"
+	case TRUE:
+		return "goson.NewBool(true)"
+	case NUMBER:
+		switch {
+		case math.IsNaN(v.num()):
+			return "goson.NewNumber(math.NaN())"
+		case math.IsInf(v.num(), 1):
+			return "goson.NewNumber(math.Inf(1))"
+		case math.IsInf(v.num(), -1):
+			return "goson.NewNumber(math.Inf(-1))"
+		default:
+			return "goson.NewNumber(" + strconv.FormatFloat(v.num(), 'g', -1, 64) + ")"
+		}
+	case STRING:
+		return "goson.NewString(" + strconv.Quote(v.str()) + ")"
+	case ARRAY:
+		s := "goson.NewArray("
+		for i, e := range v.arr() {
+			if i > 0 {
+				s += ", "
+			}
+			s += e.goString()
+		}
+		return s + ")"
+	case OBJECT:
+		s := "goson.NewObject("
+		for i, kv := range v.obj() {
+			if i > 0 {
+				s += ", "
+			}
+			s += "goson.NewKV(" + strconv.Quote(kv.k) + ", " + kv.v.goString() + ")"
+		}
+		return s + ")"
+	default:
+		panic("invalid value type")
+	}
+}
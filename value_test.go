@@ -0,0 +1,236 @@
+package goson
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"strconv"
+	"sync"
+	"testing"
+	"unsafe"
+)
+
+func TestTypeString(t *testing.T) {
+	assert.Equal(t, "null", NULL.String())
+	assert.Equal(t, "false", FALSE.String())
+	assert.Equal(t, "true", TRUE.String())
+	assert.Equal(t, "number", NUMBER.String())
+	assert.Equal(t, "string", STRING.String())
+	assert.Equal(t, "array", ARRAY.String())
+	assert.Equal(t, "object", OBJECT.String())
+	assert.Equal(t, "Type(9)", Type(9).String())
+	assert.Equal(t, "object", fmt.Sprint(OBJECT))
+}
+
+func TestConstructors(t *testing.T) {
+	var p Parser
+	v, err := p.Parse("{\"n\":null,\"f\":false,\"t\":true,\"i\":1.5,\"s\":\"abc\",\"a\":[1,[]],\"o\":{}}")
+	assert.Nil(t, err)
+	c := NewObject(
+		NewKV("n", NewNull()),
+		NewKV("f", NewBool(false)),
+		NewKV("t", NewBool(true)),
+		NewKV("i", NewNumber(1.5)),
+		NewKV("s", NewString("abc")),
+		NewKV("a", NewArray(NewNumber(1), NewArray())),
+		NewKV("o", NewObject()),
+	)
+	assert.True(t, isEqual(v, c))
+}
+
+func TestFormat(t *testing.T) {
+	var p Parser
+	v, err := p.Parse("{\"a\":[1,\"x\\ny\"],\"b\":{}}")
+	assert.Nil(t, err)
+
+	assert.Equal(t, "{\"a\":[1,\"x\\ny\"],\"b\":{}}", v.String())
+	assert.Equal(t, "{\"a\":[1,\"x\\ny\"],\"b\":{}}", fmt.Sprintf("%v", v))
+	assert.Equal(t, "{\"a\":[1,\"x\\ny\"],\"b\":{}}", fmt.Sprintf("%s", v))
+	assert.Equal(t, "{\n  \"a\": [\n    1,\n    \"x\\ny\"\n  ],\n  \"b\": {}\n}", fmt.Sprintf("%+v", v))
+	assert.Equal(t, "goson.NewObject(goson.NewKV(\"a\", goson.NewArray(goson.NewNumber(1), goson.NewString(\"x\\ny\"))), goson.NewKV(\"b\", goson.NewObject()))",
+		fmt.Sprintf("%#v", v))
+	assert.Equal(t, "\"[true]\"", fmt.Sprintf("%q", NewArray(NewBool(true))))
+	assert.Equal(t, "%!d(*goson.Value=null)", fmt.Sprintf("%d", NewNull()))
+	assert.Equal(t, "%!s(goson.Value: number not finite)", NewNumber(math.NaN()).String())
+	assert.Equal(t, "%!v(goson.Value: number not finite)", fmt.Sprint(NewArray(NewNumber(math.Inf(1)))))
+	assert.Equal(t, "%!v(goson.Value: number not finite)", fmt.Sprintf("%+v", NewNumber(math.NaN())))
+	assert.Equal(t, "goson.NewNumber(math.Inf(-1))", fmt.Sprintf("%#v", NewNumber(math.Inf(-1))))
+
+	var n *Value
+	assert.Equal(t, "<nil>", fmt.Sprint(n))
+	assert.Equal(t, "(*goson.Value)(nil)", fmt.Sprintf("%#v", n))
+}
+
+func TestValueLayout(t *testing.T) {
+	assert.LessOrEqual(t, unsafe.Sizeof(Value{}), uintptr(32))
+
+	a := make([]*Value, 2, 5)
+	var v Value
+	v.storeArray(a)
+	assert.Equal(t, 2, len(v.arr()))
+	assert.Equal(t, 5, cap(v.arr()))
+	assert.Nil(t, v.obj())
+	assert.Equal(t, "", v.str())
+
+	v.storeString("héllo")
+	assert.Equal(t, "héllo", v.str())
+	assert.Nil(t, v.arr())
+	v.storeNumber(-0.5)
+	assert.Equal(t, -0.5, v.num())
+	assert.Equal(t, "", v.str())
+}
+
+func TestValueAssignment(t *testing.T) {
+	a := parseValue(t, "{\"x\":1}")
+	b := *a
+	assert.Nil(t, b.SetAt(NewNumber(9), "y"))
+	assert.Equal(t, "{\"x\":1}", stringify(t, a))
+	assert.Equal(t, "{\"x\":1,\"y\":9}", stringify(t, &b))
+
+	a = parseValue(t, "[1,2]")
+	b = *a
+	assert.Nil(t, b.insertArrayElement(NewNumber(3), 2))
+	assert.Equal(t, "[1,2]", stringify(t, a))
+	assert.Equal(t, "[1,2,3]", stringify(t, &b))
+}
+
+func TestObjectIndex(t *testing.T) {
+	var v Value
+	v.setObject(0)
+	for i := 0; i < 100; i++ {
+		assert.Nil(t, v.setObjectValue(strconv.Itoa(i), NewNumber(float64(i))))
+	}
+	assert.NotNil(t, v.index())
+	assert.Nil(t, v.setObjectValue("50", NewNumber(-50)))
+	assert.Equal(t, 100, len(v.obj()))
+
+	for i := 0; i < 100; i += 3 {
+		assert.Nil(t, v.removeObjectValue(strconv.Itoa(i)))
+	}
+	assert.Equal(t, ErrKeyNotExist, v.removeObjectValue("0"))
+	for i, kv := range v.obj() {
+		assert.Equal(t, i, v.index()[kv.k])
+	}
+	for i := 0; i < 100; i++ {
+		e, err := v.getObjectValue(strconv.Itoa(i))
+		if i%3 == 0 {
+			assert.Equal(t, ErrKeyNotExist, err)
+			continue
+		}
+		assert.Nil(t, err)
+		n, _ := e.getNumber()
+		if i == 50 {
+			assert.Equal(t, -50.0, n)
+		} else {
+			assert.Equal(t, float64(i), n)
+		}
+	}
+
+	assert.Nil(t, v.clearObject())
+	assert.Nil(t, v.index())
+	_, err := v.getObjectValue("1")
+	assert.Equal(t, ErrKeyNotExist, err)
+}
+
+func TestObjectIndexDuplicateKeys(t *testing.T) {
+	var p Parser
+	s := "{\"a\":0,\"b\":1,\"c\":2,\"d\":3,\"e\":4,\"f\":5,\"g\":6,\"a\":7,\"h\":8}"
+	v, err := p.Parse(s)
+	assert.Nil(t, err)
+	e, err := v.getObjectValue("a")
+	assert.Nil(t, err)
+	assert.Equal(t, 0.0, e.num())
+	assert.NotNil(t, v.index())
+
+	assert.Nil(t, v.removeObjectValue("a"))
+	e, err = v.getObjectValue("a")
+	assert.Nil(t, err)
+	assert.Equal(t, 7.0, e.num())
+	assert.Nil(t, v.removeObjectValue("a"))
+	_, err = v.getObjectValue("a")
+	assert.Equal(t, ErrKeyNotExist, err)
+	e, err = v.getObjectValue("h")
+	assert.Nil(t, err)
+	assert.Equal(t, 8.0, e.num())
+}
+
+func TestObjectIndexConcurrentLookup(t *testing.T) {
+	v := parseValue(t, "{\"a\":0,\"b\":1,\"c\":2,\"d\":3,\"e\":4,\"f\":5,\"g\":6,\"h\":7}")
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			e, err := v.Lookup("h")
+			assert.Nil(t, err)
+			assert.Equal(t, 7.0, e.num())
+		}()
+	}
+	wg.Wait()
+	assert.NotNil(t, v.index())
+}
+
+func TestObjectIndexAssignment(t *testing.T) {
+	a := parseValue(t, "{\"a\":0,\"b\":1,\"c\":2,\"d\":3,\"e\":4,\"f\":5,\"g\":6,\"h\":7}")
+	_, err := a.Lookup("h")
+	assert.Nil(t, err)
+	b := *a
+	assert.Nil(t, b.SetAt(NewNumber(8), "i"))
+	assert.Nil(t, b.removeObjectValue("a"))
+	_, err = a.Lookup("i")
+	assert.Equal(t, ErrKeyNotExist, errors.Unwrap(err))
+	e, err := a.Lookup("h")
+	assert.Nil(t, err)
+	assert.Equal(t, 7.0, e.num())
+	e, err = b.Lookup("i")
+	assert.Nil(t, err)
+	assert.Equal(t, 8.0, e.num())
+}
+
+func benchmarkObjectLookup(b *testing.B, size, threshold int) {
+	saved := objectIndexThreshold
+	objectIndexThreshold = threshold
+	defer func() { objectIndexThreshold = saved }()
+
+	keys := make([]string, size)
+	var v Value
+	v.setObject(size)
+	for i := range keys {
+		keys[i] = "key" + strconv.Itoa(i)
+		_ = v.setObjectValue(keys[i], NewNumber(float64(i)))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = v.getObjectValue(keys[i%size])
+	}
+}
+
+func BenchmarkObjectLookup(b *testing.B) {
+	for _, size := range []int{2, 4, 8, 16, 32, 64, 1024} {
+		b.Run("linear/"+strconv.Itoa(size), func(b *testing.B) {
+			benchmarkObjectLookup(b, size, math.MaxInt)
+		})
+		b.Run("hashed/"+strconv.Itoa(size), func(b *testing.B) {
+			benchmarkObjectLookup(b, size, 0)
+		})
+	}
+}
+
+func BenchmarkObjectBuild(b *testing.B) {
+	for _, size := range []int{100, 10000} {
+		keys := make([]string, size)
+		for i := range keys {
+			keys[i] = "key" + strconv.Itoa(i)
+		}
+		b.Run(strconv.Itoa(size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				var v Value
+				v.setObject(0)
+				for _, k := range keys {
+					_ = v.setObjectValue(k, NewNull())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package goson
+
+// Map is a hash map keyed by the content of Values, using Hash and
+// isEqual. Keys are stored as frozen deep copies, string bytes included,
+// so later changes to the Value passed in, or the Reset of an Arena it
+// was parsed into, do not affect the map. Unlike in Go maps, a key holding
+// NaN matches itself, since Equal treats NaN as equal to NaN. The zero Map
+// is empty and ready to use.
+type Map[V any] struct {
+	buckets map[uint64][]mapEntry[V]
+	n       int
+}
+
+type mapEntry[V any] struct {
+	key *Value
+	val V
+}
+
+func (m *Map[V]) find(key *Value) (uint64, int) {
+	h := Hash(key)
+	for i, e := range m.buckets[h] {
+		if isEqual(e.key, key) {
+			return h, i
+		}
+	}
+	return h, -1
+}
+
+func (m *Map[V]) Get(key *Value) (V, bool) {
+	h, i := m.find(key)
+	if i < 0 {
+		var zero V
+		return zero, false
+	}
+	return m.buckets[h][i].val, true
+}
+
+func (m *Map[V]) Set(key *Value, val V) {
+	h, i := m.find(key)
+	if i >= 0 {
+		m.buckets[h][i].val = val
+		return
+	}
+	if m.buckets == nil {
+		m.buckets = make(map[uint64][]mapEntry[V])
+	}
+	m.buckets[h] = append(m.buckets[h], mapEntry[V]{key.Clone().Freeze(), val})
+	m.n++
+}
+
+func (m *Map[V]) Delete(key *Value) bool {
+	h, i := m.find(key)
+	if i < 0 {
+		return false
+	}
+	b := m.buckets[h]
+	if len(b) == 1 {
+		delete(m.buckets, h)
+	} else {
+		m.buckets[h] = append(b[:i:i], b[i+1:]...)
+	}
+	m.n--
+	return true
+}
+
+func (m *Map[V]) Len() int {
+	return m.n
+}
+
+// Range calls f for every entry in unspecified order until f returns
+// false. The keys passed to f are frozen.
+func (m *Map[V]) Range(f func(key *Value, val V) bool) {
+	for _, b := range m.buckets {
+		for _, e := range b {
+			if !f(e.key, e.val) {
+				return
+			}
+		}
+	}
+}
+
+// Set is a set of Values compared by content. The zero Set is empty and
+// ready to use.
+type Set struct {
+	m Map[struct{}]
+}
+
+// Add inserts a frozen copy of v and reports whether it was not already
+// present.
+func (s *Set) Add(v *Value) bool {
+	if s.Has(v) {
+		return false
+	}
+	s.m.Set(v, struct{}{})
+	return true
+}
+
+func (s *Set) Has(v *Value) bool {
+	_, ok := s.m.Get(v)
+	return ok
+}
+
+func (s *Set) Remove(v *Value) bool {
+	return s.m.Delete(v)
+}
+
+func (s *Set) Len() int {
+	return s.m.Len()
+}
+
+// Values returns the frozen members of s in unspecified order.
+func (s *Set) Values() []*Value {
+	values := make([]*Value, 0, s.m.Len())
+	s.m.Range(func(key *Value, _ struct{}) bool {
+		values = append(values, key)
+		return true
+	})
+	return values
+}
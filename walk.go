@@ -0,0 +1,118 @@
+package goson
+
+type WalkAction int
+
+const (
+	Continue WalkAction = iota
+	SkipChildren
+	Stop
+)
+
+// WalkFunc is called for every node visited by Walk. The path is only
+// valid for the duration of the call.
+type WalkFunc func(path Path, node *Value) WalkAction
+
+// Walk visits v and its descendants in pre-order, parents before their
+// children and array elements and object members in order.
+func Walk(v *Value, fn WalkFunc) {
+	walk(v, make(Path, 0, 8), fn, false)
+}
+
+// WalkPostOrder is like Walk but visits children before their parent.
+// SkipChildren has no effect, since the children have been visited
+// already.
+func WalkPostOrder(v *Value, fn WalkFunc) {
+	walk(v, make(Path, 0, 8), fn, true)
+}
+
+func walk(v *Value, path Path, fn WalkFunc, post bool) bool {
+	if !post {
+		switch fn(path, v) {
+		case Stop:
+			return false
+		case SkipChildren:
+			return true
+		}
+	}
+	switch v.t {
+	case ARRAY:
+		for i, e := range v.arr() {
+			if !walk(e, append(path, i), fn, post) {
+				return false
+			}
+		}
+	case OBJECT:
+		for _, kv := range v.obj() {
+			if !walk(kv.v, append(path, kv.k), fn, post) {
+				return false
+			}
+		}
+	}
+	if post {
+		return fn(path, v) != Stop
+	}
+	return true
+}
+
+// RewriteFunc returns the node to put in place of node, or node itself to
+// keep it, along with how to continue.
+type RewriteFunc func(path Path, node *Value) (*Value, WalkAction)
+
+// Rewrite walks v in pre-order like Walk, replacing each node by what fn
+// returns. The children of a replacement are visited in turn unless fn
+// asks to skip them. It returns the new root. Frozen containers are not
+// modified: a frozen container with a replaced child is copied, sharing
+// its other children, and the copy is frozen again. A replacement stored
+// in a frozen container is a frozen copy of what fn returned, unless that
+// was frozen already, so fn's own values stay mutable.
+func Rewrite(v *Value, fn RewriteFunc) *Value {
+	v, _ = rewrite(v, make(Path, 0, 8), fn)
+	return v
+}
+
+func rewrite(v *Value, path Path, fn RewriteFunc) (*Value, bool) {
+	v, action := fn(path, v)
+	switch action {
+	case Stop:
+		return v, false
+	case SkipChildren:
+		return v, true
+	}
+	ok, copied := true, false
+	replace := func(child *Value) *Value {
+		if !copied {
+			v, copied = v.writable(), true
+		}
+		if v.frozen && !child.frozen {
+			child = child.copy().Freeze()
+		}
+		return child
+	}
+	switch v.t {
+	case ARRAY:
+		for i, e := range v.arr() {
+			var ne *Value
+			ne, ok = rewrite(e, append(path, i), fn)
+			if ne != e {
+				ne = replace(ne)
+				v.arr()[i] = ne
+			}
+			if !ok {
+				break
+			}
+		}
+	case OBJECT:
+		for i, kv := range v.obj() {
+			var ne *Value
+			ne, ok = rewrite(kv.v, append(path, kv.k), fn)
+			if ne != kv.v {
+				ne = replace(ne)
+				v.obj()[i] = &KV{kv.k, ne}
+			}
+			if !ok {
+				break
+			}
+		}
+	}
+	return v, ok
+}
@@ -0,0 +1,79 @@
+package goson
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	v := parseValue(t, "{\"a\":[1,{\"b\":2}],\"c\":{\"d~/\":3},\"e\":4}")
+
+	var pointers, dotted []string
+	Walk(v, func(path Path, node *Value) WalkAction {
+		pointers = append(pointers, path.String())
+		dotted = append(dotted, path.Dotted())
+		return Continue
+	})
+	assert.Equal(t, []string{"", "/a", "/a/0", "/a/1", "/a/1/b", "/c", "/c/d~0~1", "/e"}, pointers)
+	assert.Equal(t, []string{"", "a", "a[0]", "a[1]", "a[1].b", "c", "c[\"d~/\"]", "e"}, dotted)
+
+	pointers = nil
+	WalkPostOrder(v, func(path Path, node *Value) WalkAction {
+		pointers = append(pointers, path.String())
+		return Continue
+	})
+	assert.Equal(t, []string{"/a/0", "/a/1/b", "/a/1", "/a", "/c/d~0~1", "/c", "/e", ""}, pointers)
+
+	pointers = nil
+	Walk(v, func(path Path, node *Value) WalkAction {
+		pointers = append(pointers, path.String())
+		if path.String() == "/a" {
+			return SkipChildren
+		}
+		if path.String() == "/c/d~0~1" {
+			return Stop
+		}
+		return Continue
+	})
+	assert.Equal(t, []string{"", "/a", "/c", "/c/d~0~1"}, pointers)
+
+	pointers = nil
+	WalkPostOrder(v, func(path Path, node *Value) WalkAction {
+		pointers = append(pointers, path.String())
+		if path.String() == "/a/1" {
+			return Stop
+		}
+		return Continue
+	})
+	assert.Equal(t, []string{"/a/0", "/a/1/b", "/a/1"}, pointers)
+}
+
+func TestRewrite(t *testing.T) {
+	v := parseValue(t, "{\"a\":[1,{\"b\":2}],\"c\":3}")
+	v = Rewrite(v, func(path Path, node *Value) (*Value, WalkAction) {
+		if node.t == NUMBER {
+			return NewNumber(node.num() * 10), Continue
+		}
+		return node, Continue
+	})
+	assert.Equal(t, "{\"a\":[10,{\"b\":20}],\"c\":30}", stringify(t, v))
+
+	v = Rewrite(v, func(path Path, node *Value) (*Value, WalkAction) {
+		if path.String() == "/a" {
+			return NewArray(NewString("x")), SkipChildren
+		}
+		return node, Continue
+	})
+	assert.Equal(t, "{\"a\":[\"x\"],\"c\":30}", stringify(t, v))
+
+	v = Rewrite(v, func(path Path, node *Value) (*Value, WalkAction) {
+		if len(path) == 0 {
+			return NewArray(node, node), Continue
+		}
+		if path.String() == "/0/a/0" {
+			return NewNull(), Stop
+		}
+		return node, Continue
+	})
+	assert.Equal(t, "[{\"a\":[null],\"c\":30},{\"a\":[null],\"c\":30}]", stringify(t, v))
+}
@@ -0,0 +1,202 @@
+package goson
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// Writer emits JSON tokens straight to an io.Writer without building a
+// Value tree. Output is buffered; call Close once the root value is done.
+// NonFinite applies to Number and Value as it does for Encoder.
+type Writer struct {
+	NonFinite NonFinitePolicy
+
+	w     *bufio.Writer
+	stack []byte
+	first bool
+	key   bool
+	done  bool
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w), first: true}
+}
+
+func (w *Writer) top() byte {
+	if len(w.stack) == 0 {
+		return 0
+	}
+	return w.stack[len(w.stack)-1]
+}
+
+func (w *Writer) beforeValue() error {
+	switch w.top() {
+	case 0:
+		if w.done {
+			return ErrWriteRootNotSingular
+		}
+	case '{':
+		if !w.key {
+			return ErrWriteMissKey
+		}
+		w.key = false
+	case '[':
+		if !w.first {
+			if err := w.w.WriteByte(','); err != nil {
+				return err
+			}
+		}
+		w.first = false
+	}
+	return nil
+}
+
+func (w *Writer) afterValue() {
+	if len(w.stack) == 0 {
+		w.done = true
+	}
+}
+
+func (w *Writer) writeScalar(s string) error {
+	if err := w.beforeValue(); err != nil {
+		return err
+	}
+	if _, err := w.w.WriteString(s); err != nil {
+		return err
+	}
+	w.afterValue()
+	return nil
+}
+
+func (w *Writer) begin(b byte) error {
+	if err := w.beforeValue(); err != nil {
+		return err
+	}
+	if err := w.w.WriteByte(b); err != nil {
+		return err
+	}
+	w.stack = append(w.stack, b)
+	w.first = true
+	return nil
+}
+
+func (w *Writer) end(open, close byte) error {
+	if w.top() != open {
+		return ErrWriteMismatchedEnd
+	}
+	if w.key {
+		return ErrWriteMissValue
+	}
+	if err := w.w.WriteByte(close); err != nil {
+		return err
+	}
+	w.stack = w.stack[:len(w.stack)-1]
+	w.first = false
+	w.afterValue()
+	return nil
+}
+
+func (w *Writer) BeginObject() error {
+	return w.begin('{')
+}
+
+func (w *Writer) EndObject() error {
+	return w.end('{', '}')
+}
+
+func (w *Writer) BeginArray() error {
+	return w.begin('[')
+}
+
+func (w *Writer) EndArray() error {
+	return w.end('[', ']')
+}
+
+func (w *Writer) Key(k string) error {
+	if w.top() != '{' || w.key {
+		return ErrWriteUnexpectedKey
+	}
+	if !w.first {
+		if err := w.w.WriteByte(','); err != nil {
+			return err
+		}
+	}
+	if err := writeString(w.w, k); err != nil {
+		return err
+	}
+	if err := w.w.WriteByte(':'); err != nil {
+		return err
+	}
+	w.first = false
+	w.key = true
+	return nil
+}
+
+func (w *Writer) String(s string) error {
+	if err := w.beforeValue(); err != nil {
+		return err
+	}
+	if err := writeString(w.w, s); err != nil {
+		return err
+	}
+	w.afterValue()
+	return nil
+}
+
+func (w *Writer) Number(n float64) error {
+	s, err := formatNumber(n, w.NonFinite)
+	if err != nil {
+		return err
+	}
+	return w.writeScalar(s)
+}
+
+func (w *Writer) Bool(b bool) error {
+	if b {
+		return w.writeScalar("true")
+	}
+	return w.writeScalar("false")
+}
+
+func (w *Writer) Null() error {
+	return w.writeScalar("null")
+}
+
+// Raw writes s as a single value after checking that it is valid JSON.
+// White space around the value is dropped; the value itself is written
+// verbatim. The check streams over s like Compact and builds no Value.
+func (w *Writer) Raw(s string) error {
+	s = strings.Trim(s, " \t\n\r")
+	if s == "" {
+		return ErrParseInvalidValue
+	}
+	f := reformatter{
+		r: bufio.NewReaderSize(strings.NewReader(s), 64),
+		w: bufio.NewWriterSize(io.Discard, 64),
+	}
+	if err := f.reformat(); err != nil {
+		return err
+	}
+	return w.writeScalar(s)
+}
+
+func (w *Writer) Value(v *Value) error {
+	e := Encoder{NonFinite: w.NonFinite}
+	s, err := e.Encode(v)
+	if err != nil {
+		return err
+	}
+	return w.writeScalar(s)
+}
+
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
+
+func (w *Writer) Close() error {
+	if len(w.stack) != 0 || !w.done {
+		return ErrWriteIncomplete
+	}
+	return w.w.Flush()
+}
@@ -0,0 +1,94 @@
+package goson
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	var sb strings.Builder
+	var p Parser
+	e, err := p.Parse("{\"x\":[1,2]}")
+	assert.Nil(t, err)
+
+	w := NewWriter(&sb)
+	assert.Nil(t, w.BeginObject())
+	assert.Nil(t, w.Key("n"))
+	assert.Nil(t, w.Null())
+	assert.Nil(t, w.Key("b"))
+	assert.Nil(t, w.Bool(true))
+	assert.Nil(t, w.Key("a"))
+	assert.Nil(t, w.BeginArray())
+	assert.Nil(t, w.Number(1.5))
+	assert.Nil(t, w.String("a\"b"))
+	assert.Nil(t, w.BeginArray())
+	assert.Nil(t, w.EndArray())
+	assert.Nil(t, w.BeginObject())
+	assert.Nil(t, w.EndObject())
+	assert.Nil(t, w.Raw(" [true, false] "))
+	assert.Nil(t, w.EndArray())
+	assert.Nil(t, w.Key("v"))
+	assert.Nil(t, w.Value(e))
+	assert.Nil(t, w.EndObject())
+	assert.Nil(t, w.Close())
+
+	s := "{\"n\":null,\"b\":true,\"a\":[1.5,\"a\\\"b\",[],{},[true, false]],\"v\":{\"x\":[1,2]}}"
+	assert.Equal(t, s, sb.String())
+	_, err = p.Parse(sb.String())
+	assert.Nil(t, err)
+}
+
+func TestWriterError(t *testing.T) {
+	var sb strings.Builder
+
+	w := NewWriter(&sb)
+	assert.Nil(t, w.BeginObject())
+	assert.Equal(t, ErrWriteMissKey, w.Number(1))
+	assert.Equal(t, ErrWriteMismatchedEnd, w.EndArray())
+	assert.Nil(t, w.Key("a"))
+	assert.Equal(t, ErrWriteUnexpectedKey, w.Key("b"))
+	assert.Equal(t, ErrWriteMissValue, w.EndObject())
+	assert.Equal(t, ErrWriteIncomplete, w.Close())
+
+	w = NewWriter(&sb)
+	assert.Equal(t, ErrWriteUnexpectedKey, w.Key("a"))
+	assert.Equal(t, ErrWriteMismatchedEnd, w.EndObject())
+	assert.Equal(t, ErrWriteIncomplete, w.Close())
+	assert.Nil(t, w.BeginArray())
+	assert.Equal(t, ErrWriteUnexpectedKey, w.Key("a"))
+	assert.Equal(t, ErrParseInvalidValue, w.Raw(""))
+	assert.Equal(t, ErrParseMissCommaOrSquareBracket, w.Raw("[1"))
+	assert.Equal(t, ErrParseRootNotSingular, w.Raw("1 2"))
+	assert.Equal(t, ErrParseInvalidStringEscape, w.Raw("\"\\x\""))
+	assert.Nil(t, w.EndArray())
+	assert.Equal(t, ErrWriteRootNotSingular, w.Null())
+	assert.Nil(t, w.Close())
+}
+
+func TestWriterLongString(t *testing.T) {
+	s := strings.Repeat("ab\"c\n", 1<<20)
+	var sb strings.Builder
+	w := NewWriter(&sb)
+	assert.Nil(t, w.BeginObject())
+	assert.Nil(t, w.Key(s))
+	assert.Nil(t, w.String(s))
+	assert.Nil(t, w.EndObject())
+	assert.Nil(t, w.Close())
+	q := stringifyString(s)
+	assert.Equal(t, 2+5<<20+2<<20, len(q))
+	assert.Equal(t, "{"+q+":"+q+"}", sb.String())
+}
+
+func TestWriterNonFinite(t *testing.T) {
+	var sb strings.Builder
+	w := NewWriter(&sb)
+	assert.Nil(t, w.BeginArray())
+	assert.Equal(t, ErrNumberNotFinite, w.Number(math.NaN()))
+	w.NonFinite = NonFiniteNull
+	assert.Nil(t, w.Number(math.Inf(1)))
+	assert.Nil(t, w.EndArray())
+	assert.Nil(t, w.Close())
+	assert.Equal(t, "[null]", sb.String())
+}